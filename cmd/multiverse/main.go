@@ -28,8 +28,12 @@ func main() {
 		log.Fatal(err)
 	}
 
+	cleanup, stop := context.WithCancel(context.Background())
+	defer stop()
+
 	web := http.NewServer(server)
 	go web.ListenAndServe()
+	go server.Cleanup(cleanup)
 
 	fmt.Print(banner)
 	fmt.Println("your peer id is", server.Node.Identity)
@@ -44,6 +48,7 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	stop()
 	web.Shutdown(ctx)
 	server.Node.Close()
 }
@@ -12,14 +12,18 @@ require (
 	github.com/ipfs/go-ipfs v0.8.0
 	github.com/ipfs/go-ipfs-chunker v0.0.5
 	github.com/ipfs/go-ipfs-config v0.12.0
+	github.com/ipfs/go-ipfs-pinner v0.1.1
 	github.com/ipfs/go-ipld-format v0.2.0
 	github.com/ipfs/go-merkledag v0.3.2
 	github.com/ipfs/go-unixfs v0.2.4
 	github.com/ipfs/interface-go-ipfs-core v0.4.0
+	github.com/microcosm-cc/bluemonday v1.0.6
 	github.com/multiformats/go-multihash v0.0.15 // indirect
+	github.com/niklasfasching/go-org v1.5.0
 	github.com/yuin/goldmark v1.3.3
 	github.com/yuin/goldmark-highlighting v0.0.0-20200307114337-60d527fdb691
 	golang.org/x/crypto v0.0.0-20210220033148-5ea612d1eb83
+	golang.org/x/net v0.0.0-20210331212208-0fccb6fa2b5c
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c
 	gorm.io/driver/sqlite v1.1.4
 	gorm.io/gorm v1.21.6
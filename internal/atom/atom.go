@@ -0,0 +1,117 @@
+package atom
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	// Namespace is the Atom XML namespace.
+	Namespace = "http://www.w3.org/2005/Atom"
+	// ContentType is the media type of Atom feeds.
+	ContentType = "application/atom+xml; charset=utf-8"
+	// MaxAge is how long clients can cache feeds before revalidating.
+	MaxAge = 5 * time.Minute
+)
+
+// Feed is an Atom feed document.
+type Feed struct {
+	XMLName xml.Name  `xml:"feed"`
+	Xmlns   string    `xml:"xmlns,attr"`
+	ID      string    `xml:"id"`
+	Title   string    `xml:"title"`
+	Updated time.Time `xml:"updated"`
+	Links   []Link    `xml:"link"`
+	Entries []Entry   `xml:"entry"`
+}
+
+// Entry is a single item of a feed.
+type Entry struct {
+	ID      string    `xml:"id"`
+	Title   string    `xml:"title"`
+	Updated time.Time `xml:"updated"`
+	Author  *Person   `xml:"author,omitempty"`
+	Links   []Link    `xml:"link"`
+	Content *Text     `xml:"content,omitempty"`
+}
+
+// Link references a web resource.
+type Link struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+// Person is the author of an entry.
+type Person struct {
+	Name string `xml:"name"`
+}
+
+// Text is text or html content.
+type Text struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// BaseURL returns the scheme and host the request was made to.
+//
+// Feed readers require absolute links.
+func BaseURL(req *http.Request) string {
+	if req.TLS != nil {
+		return "https://" + req.Host
+	}
+
+	return "http://" + req.Host
+}
+
+// Write encodes the feed to the writer.
+//
+// The feed is updated at the time of its newest entry.
+func Write(w io.Writer, feed *Feed) error {
+	feed.Xmlns = Namespace
+	for _, e := range feed.Entries {
+		if e.Updated.After(feed.Updated) {
+			feed.Updated = e.Updated
+		}
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	return enc.Encode(feed)
+}
+
+// Serve writes the feed as the response to the request.
+//
+// The ETag is a hash of the feed and Last-Modified is the time of the
+// newest entry, so feed readers can poll with conditional requests.
+// Only public feeds can be stored by shared caches.
+func Serve(w http.ResponseWriter, req *http.Request, feed *Feed, public bool) {
+	var b bytes.Buffer
+	if err := Write(&b, feed); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	cache := "private"
+	if public {
+		cache = "public"
+	}
+
+	sum := sha256.Sum256(b.Bytes())
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("Cache-Control", fmt.Sprintf("%s, max-age=%d", cache, int(MaxAge.Seconds())))
+	w.Header().Set("Vary", "Cookie")
+	w.Header().Set("Etag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	http.ServeContent(w, req, "", feed.Updated, bytes.NewReader(b.Bytes()))
+}
@@ -0,0 +1,144 @@
+package codesearch
+
+import (
+	"context"
+	"errors"
+
+	"github.com/alecthomas/chroma/lexers"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	ipld "github.com/ipfs/go-ipld-format"
+	"gorm.io/gorm"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/gitutil"
+)
+
+// MaxFileSize is the largest file size that is indexed.
+const MaxFileSize = 1024 * 1024
+
+// batchSize is the number of files written or read at once.
+const batchSize = 100
+
+// Index replaces the indexed files of the repo with the
+// text files found on its default branch.
+//
+// Encrypted repos are not indexed because the index stores
+// file contents in plain text. If the repo changes while
+// indexing, its latest version is indexed instead.
+func Index(ctx context.Context, db *gorm.DB, ds ipld.DAGService, repo *database.Repo) error {
+	if repo.Encrypted {
+		return database.DeleteFilesByRepoID(db, repo.ID)
+	}
+
+	git, err := gitutil.Open(ctx, ds, repo.CID, nil)
+	if err != nil {
+		return err
+	}
+
+	head, err := gitutil.HeadOrDefault(git)
+	if err != nil {
+		return err
+	}
+
+	var tree *object.Tree
+	if head != nil {
+		commit, err := gitutil.Commit(git, head)
+		if err != nil {
+			return err
+		}
+
+		if tree, err = commit.Tree(); err != nil {
+			return err
+		}
+	}
+
+	var current database.Repo
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// the repo may have been deleted while indexing
+		err := tx.First(&current, repo.ID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// the repo changed while indexing so the stale files are kept
+		// until the latest version is indexed below
+		if current.CID != repo.CID {
+			return nil
+		}
+
+		if err := database.DeleteFilesByRepoID(tx, repo.ID); err != nil {
+			return err
+		}
+
+		if tree == nil {
+			return nil
+		}
+
+		var files []database.File
+		err = tree.Files().ForEach(func(f *object.File) error {
+			if f.Mode != filemode.Regular && f.Mode != filemode.Executable {
+				return nil
+			}
+
+			if f.Size > MaxFileSize {
+				return nil
+			}
+
+			binary, err := f.IsBinary()
+			if err != nil || binary {
+				return err
+			}
+
+			content, err := f.Contents()
+			if err != nil {
+				return err
+			}
+
+			files = append(files, database.File{
+				RepoID:   repo.ID,
+				Path:     f.Name,
+				Language: Language(f.Name, content),
+				Content:  content,
+			})
+
+			if len(files) < batchSize {
+				return nil
+			}
+
+			err = tx.Create(&files).Error
+			files = nil
+			return err
+		})
+
+		if err != nil || len(files) == 0 {
+			return err
+		}
+
+		return tx.Create(&files).Error
+	})
+
+	if err != nil || current.ID == 0 || current.CID == repo.CID {
+		return err
+	}
+
+	return Index(ctx, db, ds, &current)
+}
+
+// Language returns the name of the programming language of the file.
+func Language(name, content string) string {
+	lexer := lexers.Match(name)
+	if lexer == nil {
+		lexer = lexers.Analyse(content)
+	}
+
+	if lexer == nil {
+		return ""
+	}
+
+	return lexer.Config().Name
+}
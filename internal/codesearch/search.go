@@ -0,0 +1,221 @@
+package codesearch
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+)
+
+const (
+	// MaxLines is the maximum number of matching lines returned per file.
+	MaxLines = 5
+	// SnippetContext is the number of characters shown around a match.
+	SnippetContext = 80
+)
+
+const (
+	// MaxPatternLength is the longest search text accepted.
+	MaxPatternLength = 256
+	// MaxScanFiles is the maximum number of files scanned per search.
+	MaxScanFiles = 10000
+	// MaxScanBytes is the maximum number of content bytes scanned per search.
+	MaxScanBytes = 64 * 1024 * 1024
+	// Timeout is the maximum duration of a search.
+	Timeout = 5 * time.Second
+)
+
+var (
+	// ErrPatternLength is returned when the search text is too long.
+	ErrPatternLength = errors.New("search text is too long")
+	// ErrScanLimit is returned with partial results when a search scans too much.
+	ErrScanLimit = errors.New("search stopped early, narrow it down with a path or language")
+)
+
+// Query contains search parameters.
+type Query struct {
+	// Text is the substring or regular expression to search for.
+	Text string
+	// Regex enables regular expression matching.
+	Regex bool
+	// Path filters files whose path contains the value.
+	Path string
+	// Language filters files by language name.
+	Language string
+	// RepoID limits the search to a single repo when non zero.
+	RepoID uint
+	// Viewer limits the search to repos the user can read.
+	Viewer *database.User
+	// Offset is the number of matching files to skip.
+	Offset int
+	// Limit is the maximum number of matching files to return.
+	Limit int
+}
+
+// Line is a snippet of a matching line.
+type Line struct {
+	// Number is the line number starting from one.
+	Number int
+	// Before is the text preceding the match.
+	Before string
+	// Match is the matching text.
+	Match string
+	// After is the text following the match.
+	After string
+}
+
+// Result contains a matching file and its matching lines.
+type Result struct {
+	// File is the matching file.
+	File database.File
+	// Lines contains the first matching lines.
+	Lines []Line
+}
+
+// Search returns the files matching the query.
+//
+// Searches that exceed the scan limits or time out return
+// the results found so far together with ErrScanLimit.
+func Search(ctx context.Context, db *gorm.DB, q Query) ([]Result, error) {
+	if len(q.Text) > MaxPatternLength {
+		return nil, ErrPatternLength
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	expr := q.Text
+	if !q.Regex {
+		expr = "(?i)" + regexp.QuoteMeta(q.Text)
+	}
+
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := db.WithContext(ctx).Model(&database.File{}).Preload("Repo.User").Order("repo_id, path")
+	if q.RepoID != 0 {
+		tx = tx.Where("repo_id = ?", q.RepoID)
+	} else {
+		visible := db.Model(&database.Repo{}).Select("id").Scopes(database.VisibleRepos(q.Viewer))
+		tx = tx.Where("repo_id IN (?)", visible)
+	}
+
+	if q.Path != "" {
+		tx = tx.Where(`path LIKE ? ESCAPE '\'`, "%"+escapeLike(q.Path)+"%")
+	}
+
+	if q.Language != "" {
+		tx = tx.Where("language = ? COLLATE NOCASE", q.Language)
+	}
+
+	// substring queries can be narrowed down by the database
+	if !q.Regex {
+		tx = tx.Where(`content LIKE ? ESCAPE '\'`, "%"+escapeLike(q.Text)+"%")
+	}
+
+	tx = tx.Session(&gorm.Session{})
+	skip := q.Offset
+	scanned := 0
+
+	var results []Result
+	for offset := 0; len(results) < q.Limit; offset += batchSize {
+		if offset >= MaxScanFiles || ctx.Err() != nil {
+			return results, ErrScanLimit
+		}
+
+		var files []database.File
+		if err := tx.Offset(offset).Limit(batchSize).Find(&files).Error; err != nil {
+			if ctx.Err() != nil {
+				return results, ErrScanLimit
+			}
+
+			return nil, err
+		}
+
+		for _, f := range files {
+			scanned += len(f.Content)
+			if scanned > MaxScanBytes || ctx.Err() != nil {
+				return results, ErrScanLimit
+			}
+
+			lines := match(pattern, f.Content)
+			if len(lines) == 0 {
+				continue
+			}
+
+			if skip > 0 {
+				skip--
+				continue
+			}
+
+			f.Content = ""
+			results = append(results, Result{f, lines})
+
+			if len(results) >= q.Limit {
+				break
+			}
+		}
+
+		if len(files) < batchSize {
+			break
+		}
+	}
+
+	return results, nil
+}
+
+// match returns snippets of the lines matching the pattern.
+func match(pattern *regexp.Regexp, content string) []Line {
+	var lines []Line
+	for i, text := range strings.Split(content, "\n") {
+		loc := pattern.FindStringIndex(text)
+		if loc == nil || loc[0] == loc[1] {
+			continue
+		}
+
+		lines = append(lines, Line{
+			Number: i + 1,
+			Before: truncateLeft(text[:loc[0]], SnippetContext),
+			Match:  text[loc[0]:loc[1]],
+			After:  truncateRight(text[loc[1]:], SnippetContext),
+		})
+
+		if len(lines) >= MaxLines {
+			break
+		}
+	}
+
+	return lines
+}
+
+// escapeLike escapes the special characters of a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// truncateLeft keeps the last n runes of s.
+func truncateLeft(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+
+	return "…" + string(r[len(r)-n:])
+}
+
+// truncateRight keeps the first n runes of s.
+func truncateRight(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+
+	return string(r[:n]) + "…"
+}
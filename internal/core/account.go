@@ -0,0 +1,99 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/mail"
+)
+
+const (
+	// PasswordResetExpiry is how long password reset links can be used.
+	PasswordResetExpiry = time.Hour
+	// VerifyEmailExpiry is how long email verification links can be used.
+	VerifyEmailExpiry = 24 * time.Hour
+)
+
+// ErrMailDisabled is returned when sending email without a configured mailer.
+var ErrMailDisabled = errors.New("email delivery is not configured on this server")
+
+// SendPasswordReset emails the user a link to set a new password.
+// Previous reset links of the user stop working.
+//
+// Links are relative to the server BaseURL.
+func (s *Server) SendPasswordReset(user *database.User) error {
+	token, err := s.createUserToken(user, database.TokenPasswordReset, PasswordResetExpiry)
+	if err != nil {
+		return err
+	}
+
+	msg := mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Someone requested a password reset for %s.\n\n"+
+			"Open the link below to choose a new password. It can only be used once and expires soon.\n\n"+
+			"%s/_password_reset/%s\n\n"+
+			"If you did not request this, you can ignore this email.\n",
+			user.Username, s.BaseURL, token.Secret),
+	}
+
+	s.sendMail(&msg)
+	return nil
+}
+
+// SendEmailVerification emails the user a link to confirm their email address.
+// Previous verification links of the user stop working.
+//
+// Links are relative to the server BaseURL.
+func (s *Server) SendEmailVerification(user *database.User) error {
+	token, err := s.createUserToken(user, database.TokenVerifyEmail, VerifyEmailExpiry)
+	if err != nil {
+		return err
+	}
+
+	msg := mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Open the link below to verify the email address of %s:\n\n%s/_verify_email/%s\n",
+			user.Username, s.BaseURL, token.Secret),
+	}
+
+	s.sendMail(&msg)
+	return nil
+}
+
+// createUserToken replaces the tokens of the user with the given purpose with a new one.
+func (s *Server) createUserToken(user *database.User, purpose string, expiry time.Duration) (*database.UserToken, error) {
+	if s.Mailer == nil {
+		return nil, ErrMailDisabled
+	}
+
+	if err := database.DeleteUserTokens(s.DB, user.ID, purpose); err != nil {
+		return nil, err
+	}
+
+	token := database.UserToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(expiry),
+	}
+
+	if err := token.Create(s.DB); err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// sendMail sends the message in the background and logs errors.
+func (s *Server) sendMail(msg *mail.Message) {
+	go func() {
+		if err := s.Mailer.Send(msg); err != nil {
+			log.Println(err)
+		}
+	}()
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/mail"
+)
+
+func TestAccountLinks(t *testing.T) {
+	db := openTestDB(t)
+	mailer := make(testMailer, 10)
+
+	s := Server{
+		DB:      db,
+		Mailer:  mailer,
+		BaseURL: "https://multiverse.example.com",
+	}
+
+	alice := createTestUser(t, db, "alice")
+
+	tests := []struct {
+		send func() error
+		link string
+	}{
+		{func() error { return s.SendPasswordReset(alice) }, "https://multiverse.example.com/_password_reset/"},
+		{func() error { return s.SendEmailVerification(alice) }, "https://multiverse.example.com/_verify_email/"},
+	}
+
+	for _, test := range tests {
+		if err := test.send(); err != nil {
+			t.Fatalf("failed to send email: %v", err)
+		}
+
+		var msg *mail.Message
+		select {
+		case msg = <-mailer:
+		case <-time.After(time.Second):
+			t.Fatal("no email was sent")
+		}
+
+		if msg.To != alice.Email {
+			t.Errorf("email sent to %q, want %q", msg.To, alice.Email)
+		}
+
+		if !strings.Contains(msg.Body, "\n"+test.link) {
+			t.Errorf("body %q does not contain %q", msg.Body, test.link)
+		}
+	}
+}
+
+func TestAccountLinksMailDisabled(t *testing.T) {
+	db := openTestDB(t)
+	s := Server{DB: db}
+
+	alice := createTestUser(t, db, "alice")
+	if err := s.SendPasswordReset(alice); err != ErrMailDisabled {
+		t.Errorf("SendPasswordReset() error = %v, want %v", err, ErrMailDisabled)
+	}
+}
+
+func TestUserTokenSingleUse(t *testing.T) {
+	db := openTestDB(t)
+
+	alice := createTestUser(t, db, "alice")
+	token := database.UserToken{
+		UserID:    alice.ID,
+		Purpose:   database.TokenPasswordReset,
+		Email:     alice.Email,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	if err := token.Create(db); err != nil {
+		t.Fatalf("failed to create token: %v", err)
+	}
+
+	if err := token.Consume(db); err != nil {
+		t.Fatalf("failed to consume token: %v", err)
+	}
+
+	if err := token.Consume(db); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Consume() error = %v, want %v", err, gorm.ErrRecordNotFound)
+	}
+}
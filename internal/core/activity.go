@@ -0,0 +1,51 @@
+package core
+
+import (
+	"context"
+
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/gitutil"
+)
+
+// RecordPush records the update of a ref by the user as push activity.
+//
+// The from hash is zero for created refs and the to hash is zero for deleted refs.
+func (s *Server) RecordPush(ctx context.Context, repo *database.Repo, userID uint, name plumbing.ReferenceName, from, to plumbing.Hash) error {
+	activity := database.Activity{
+		UserID: userID,
+		RepoID: repo.ID,
+		Type:   database.ActivityPush,
+		Ref:    name.String(),
+		Old:    from.String(),
+		New:    to.String(),
+	}
+
+	if !to.IsZero() {
+		git, err := s.OpenRepo(ctx, repo)
+		if err != nil {
+			return err
+		}
+
+		commits, err := gitutil.Compare(git, from, to)
+		if err != nil {
+			return err
+		}
+
+		activity.Commits = len(commits)
+	}
+
+	return activity.Save(s.DB)
+}
+
+// RecordActivity records an action of the user on the repo that does not update refs.
+func (s *Server) RecordActivity(userID uint, repo *database.Repo, typ string) error {
+	activity := database.Activity{
+		UserID: userID,
+		RepoID: repo.ID,
+		Type:   typ,
+	}
+
+	return activity.Save(s.DB)
+}
@@ -0,0 +1,35 @@
+package core
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+)
+
+// CleanupInterval is how often expired sessions and tokens are removed.
+const CleanupInterval = time.Hour
+
+// Cleanup periodically removes expired sessions and user tokens
+// until the context is canceled.
+func (s *Server) Cleanup(ctx context.Context) {
+	ticker := time.NewTicker(CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := database.DeleteExpiredSessions(s.DB); err != nil {
+			log.Println(err)
+		}
+
+		if err := database.DeleteExpiredUserTokens(s.DB); err != nil {
+			log.Println(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
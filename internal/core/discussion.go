@@ -0,0 +1,334 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/gitutil"
+)
+
+// DiscussionRef is the ref containing the issues and pull requests of a repo.
+//
+// It is outside of refs/heads and refs/tags so git clients do not fetch
+// it by default, but it is part of the repo content and CID.
+const DiscussionRef = plumbing.ReferenceName("refs/meta/discussion")
+
+// Record is a signed discussion record.
+//
+// The signature is made with the IPFS node key over the type, a newline
+// and the data. Signer is the peer ID of the node and PublicKey its
+// marshaled public key, so the record can be verified on any node.
+type Record struct {
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	Signer    string          `json:"signer"`
+	PublicKey []byte          `json:"public_key"`
+	Signature []byte          `json:"signature"`
+}
+
+type issueRecord struct {
+	Number    uint      `json:"number"`
+	Author    string    `json:"author"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	State     string    `json:"state"`
+	Labels    []string  `json:"labels"`
+	Assignees []string  `json:"assignees"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type pullRecord struct {
+	Number    uint      `json:"number"`
+	Author    string    `json:"author"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	State     string    `json:"state"`
+	Base      string    `json:"base"`
+	HeadRepo  string    `json:"head_repo"`
+	Head      string    `json:"head"`
+	MergeBase string    `json:"merge_base,omitempty"`
+	HeadHash  string    `json:"head_hash,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type commentRecord struct {
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	Path      string    `json:"path,omitempty"`
+	Line      int       `json:"line,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type reviewRecord struct {
+	Author    string    `json:"author"`
+	State     string    `json:"state"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PublishDiscussion writes the issues and pull requests of the repo with
+// their comments and reviews as signed records to the DiscussionRef.
+//
+// Every publish contains all discussions, so a failed publish is
+// corrected by the next one. Records that are already published are kept
+// as they are, and nothing is committed when no record has changed.
+// Callers must hold the pin lock and the repo lock.
+func (s *Server) PublishDiscussion(ctx context.Context, repo *database.Repo) error {
+	published, err := s.publishedRecords(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	files, changed, err := s.discussionFiles(repo, published)
+	if err != nil {
+		return err
+	}
+
+	if !changed {
+		return nil
+	}
+
+	sig := object.Signature{
+		Name: "multiverse",
+		When: time.Now(),
+	}
+
+	return s.UpdateRepo(ctx, repo, func(git *git.Repository) error {
+		_, err := gitutil.CommitFiles(git, DiscussionRef, files, sig, "Update discussion")
+		return err
+	})
+}
+
+// publishedRecords returns the records in the DiscussionRef of the repo by path.
+func (s *Server) publishedRecords(ctx context.Context, repo *database.Repo) (map[string][]byte, error) {
+	git, err := s.OpenRepo(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	ref, err := git.Reference(DiscussionRef, true)
+	if err == plumbing.ErrReferenceNotFound {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := git.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	iter, err := commit.Files()
+	if err != nil {
+		return nil, err
+	}
+
+	records := make(map[string][]byte)
+	err = iter.ForEach(func(f *object.File) error {
+		content, err := f.Contents()
+		records[f.Name] = []byte(content)
+		return err
+	})
+
+	return records, err
+}
+
+// discussionFiles returns the signed records of the repo by path and
+// whether they differ from the published records.
+//
+// Published records with unchanged data are reused instead of signed again.
+func (s *Server) discussionFiles(repo *database.Repo, published map[string][]byte) (map[string][]byte, bool, error) {
+	var changed bool
+
+	files := make(map[string][]byte)
+	add := func(path, typ string, v interface{}) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+
+		if s.isPublished(published[path], typ, data) {
+			files[path] = published[path]
+			return nil
+		}
+
+		record, err := s.signRecord(typ, data)
+		if err != nil {
+			return err
+		}
+
+		files[path] = record
+		changed = true
+		return nil
+	}
+
+	for _, state := range []string{database.IssueOpen, database.IssueClosed} {
+		issues, err := database.FindIssuesByRepoID(s.DB, repo.ID, state, "")
+		if err != nil {
+			return nil, false, err
+		}
+
+		for _, issue := range issues {
+			dir := fmt.Sprintf("issues/%d", issue.Number)
+			if err := add(dir+"/issue.json", "issue", newIssueRecord(&issue)); err != nil {
+				return nil, false, err
+			}
+
+			comments, err := database.FindIssueCommentsByIssueID(s.DB, issue.ID)
+			if err != nil {
+				return nil, false, err
+			}
+
+			for _, c := range comments {
+				record := commentRecord{Author: c.User.Username, Body: c.Body, CreatedAt: c.CreatedAt}
+				if err := add(fmt.Sprintf("%s/comments/%d.json", dir, c.ID), "issue_comment", record); err != nil {
+					return nil, false, err
+				}
+			}
+		}
+	}
+
+	for _, state := range []string{database.PullOpen, database.PullClosed, database.PullMerged} {
+		pulls, err := database.FindPullRequestsByRepoID(s.DB, repo.ID, state)
+		if err != nil {
+			return nil, false, err
+		}
+
+		for _, pull := range pulls {
+			dir := fmt.Sprintf("pulls/%d", pull.Number)
+			if err := add(dir+"/pull.json", "pull_request", newPullRecord(&pull)); err != nil {
+				return nil, false, err
+			}
+
+			comments, err := database.FindPullCommentsByPullRequestID(s.DB, pull.ID)
+			if err != nil {
+				return nil, false, err
+			}
+
+			for _, c := range comments {
+				record := commentRecord{Author: c.User.Username, Body: c.Body, Path: c.Path, Line: c.Line, CreatedAt: c.CreatedAt}
+				if err := add(fmt.Sprintf("%s/comments/%d.json", dir, c.ID), "pull_comment", record); err != nil {
+					return nil, false, err
+				}
+			}
+
+			reviews, err := database.FindPullReviewsByPullRequestID(s.DB, pull.ID)
+			if err != nil {
+				return nil, false, err
+			}
+
+			for _, r := range reviews {
+				record := reviewRecord{Author: r.User.Username, State: r.State, Body: r.Body, CreatedAt: r.CreatedAt}
+				if err := add(fmt.Sprintf("%s/reviews/%d.json", dir, r.ID), "pull_review", record); err != nil {
+					return nil, false, err
+				}
+			}
+		}
+	}
+
+	// records of deleted comments are no longer published
+	changed = changed || len(files) != len(published)
+	return files, changed, nil
+}
+
+// isPublished returns true if the published record was signed by this
+// node and has the given type and encoded data.
+func (s *Server) isPublished(published []byte, typ string, data []byte) bool {
+	if published == nil {
+		return false
+	}
+
+	var record Record
+	if err := json.Unmarshal(published, &record); err != nil {
+		return false
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, record.Data); err != nil {
+		return false
+	}
+
+	return record.Type == typ && record.Signer == s.Node.Identity.String() && bytes.Equal(compact.Bytes(), data)
+}
+
+// signRecord returns the encoded record of the given type and encoded data.
+func (s *Server) signRecord(typ string, data []byte) ([]byte, error) {
+	key := s.Node.PrivateKey
+	signature, err := key.Sign(append([]byte(typ+"\n"), data...))
+	if err != nil {
+		return nil, err
+	}
+
+	public, err := key.GetPublic().Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	record := Record{
+		Type:      typ,
+		Data:      data,
+		Signer:    s.Node.Identity.String(),
+		PublicKey: public,
+		Signature: signature,
+	}
+
+	return json.MarshalIndent(record, "", "\t")
+}
+
+func newIssueRecord(issue *database.Issue) issueRecord {
+	record := issueRecord{
+		Number:    issue.Number,
+		Author:    issue.User.Username,
+		Title:     issue.Title,
+		Body:      issue.Body,
+		State:     issue.State,
+		Labels:    []string{},
+		Assignees: []string{},
+		CreatedAt: issue.CreatedAt,
+		UpdatedAt: issue.UpdatedAt,
+	}
+
+	for _, l := range issue.Labels {
+		record.Labels = append(record.Labels, l.Name)
+	}
+
+	for _, u := range issue.Assignees {
+		record.Assignees = append(record.Assignees, u.Username)
+	}
+
+	return record
+}
+
+func newPullRecord(pull *database.PullRequest) pullRecord {
+	var head string
+	if pull.HeadRepo.ID != 0 {
+		head = pull.HeadRepo.User.Username + "/" + pull.HeadRepo.Name
+	}
+
+	return pullRecord{
+		Number:    pull.Number,
+		Author:    pull.User.Username,
+		Title:     pull.Title,
+		Body:      pull.Body,
+		State:     pull.State,
+		Base:      pull.BaseRef,
+		HeadRepo:  head,
+		Head:      pull.HeadRef,
+		MergeBase: pull.MergeBase,
+		HeadHash:  pull.HeadHash,
+		CreatedAt: pull.CreatedAt,
+		UpdatedAt: pull.UpdatedAt,
+	}
+}
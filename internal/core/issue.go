@@ -0,0 +1,51 @@
+package core
+
+import (
+	"context"
+
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/gitutil"
+)
+
+// CloseIssues closes the issues referenced by closing keywords in the
+// commits added to the ref when it is the default branch of the repo.
+//
+// The from hash is zero for newly created refs.
+// Callers must hold the pin lock and the repo lock.
+func (s *Server) CloseIssues(ctx context.Context, repo *database.Repo, name plumbing.ReferenceName, from, to plumbing.Hash) error {
+	if to.IsZero() {
+		return nil
+	}
+
+	git, err := s.OpenRepo(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	head, err := git.Reference(plumbing.HEAD, false)
+	if err != nil || head.Target() != name {
+		return err
+	}
+
+	commits, err := gitutil.Compare(git, from, to)
+	if err != nil {
+		return err
+	}
+
+	var numbers []uint
+	for _, commit := range commits {
+		numbers = append(numbers, gitutil.ClosingIssues(commit.Message)...)
+	}
+
+	if len(numbers) == 0 {
+		return nil
+	}
+
+	if err := database.CloseIssuesByNumbers(s.DB, repo.ID, numbers); err != nil {
+		return err
+	}
+
+	return s.PublishDiscussion(ctx, repo)
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"os"
+	"strconv"
+
+	"gorm.io/gorm"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/pkg/storage"
+)
+
+// RepoKey returns the encryption key of the repo or nil if it is not encrypted.
+//
+// Repository encryption is server-held: it keeps contents private on the
+// IPFS network, but the server unwraps the key of every repo with its own
+// key. Who can read a repo is only decided by Repo.CanRead, so there are
+// no per-user copies of the key.
+func (s *Server) RepoKey(repo *database.Repo) ([]byte, error) {
+	if !repo.Encrypted {
+		return nil, nil
+	}
+
+	var wrapped database.RepoKey
+	if err := wrapped.FindByRepoID(s.DB, repo.ID); err != nil {
+		return nil, err
+	}
+
+	return storage.UnwrapKey(s.repoKEK(repo.ID), wrapped.Key)
+}
+
+// SaveRepoKey wraps and stores the encryption key of the repo.
+func (s *Server) SaveRepoKey(db *gorm.DB, repo *database.Repo, key []byte) error {
+	wrapped, err := storage.WrapKey(s.repoKEK(repo.ID), key)
+	if err != nil {
+		return err
+	}
+
+	record := database.RepoKey{RepoID: repo.ID, Key: wrapped}
+	return record.Create(db)
+}
+
+// repoKEK derives the key encryption key of a repo from the server key.
+func (s *Server) repoKEK(repoID uint) []byte {
+	mac := hmac.New(sha256.New, s.Key)
+	mac.Write([]byte("repo/" + strconv.FormatUint(uint64(repoID), 10)))
+	return mac.Sum(nil)
+}
+
+// loadKey reads the server key from the given path
+// or generates a new one if it does not exist.
+func loadKey(kpath string) ([]byte, error) {
+	key, err := os.ReadFile(kpath)
+	if err == nil && len(key) != storage.KeySize {
+		return nil, storage.ErrInvalidKey
+	}
+
+	if !os.IsNotExist(err) {
+		return key, err
+	}
+
+	key, err = storage.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return key, os.WriteFile(kpath, key, 0600)
+}
@@ -0,0 +1,112 @@
+package core
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/mail"
+)
+
+// mentionPattern matches @username mentions that are not part of an email address or path.
+var mentionPattern = regexp.MustCompile(`(?:^|[^a-zA-Z0-9_@./-])@([a-zA-Z0-9]+(?:[-_]?[a-zA-Z0-9]+)+)`)
+
+// Notice is an event on a repository that users are notified of.
+type Notice struct {
+	// Actor is the user causing the event.
+	Actor *database.User
+	// Repo is the repository the event occurred on. The owner must be loaded.
+	Repo *database.Repo
+	// Type is the notification type for watchers.
+	Type string
+	// Title is a summary of the event.
+	Title string
+	// Link is the path of the page showing the event.
+	Link string
+	// Text is searched for mentioned users.
+	Text string
+}
+
+// Mentions returns the usernames mentioned in the text.
+func Mentions(text string) []string {
+	seen := make(map[string]bool)
+
+	var usernames []string
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			usernames = append(usernames, match[1])
+		}
+	}
+
+	return usernames
+}
+
+// Notify notifies the watchers of the repo and the users mentioned in the
+// notice text. The actor and users who cannot read the repo are skipped.
+//
+// Emails are sent in the background to users who enabled them and
+// verified their address, with links relative to the server BaseURL.
+func (s *Server) Notify(notice *Notice) error {
+	recipients := make(map[uint]database.Notification)
+
+	watches, err := database.FindWatchesByRepoID(s.DB, notice.Repo.ID)
+	if err != nil {
+		return err
+	}
+
+	users := make(map[uint]database.User)
+	for _, watch := range watches {
+		users[watch.UserID] = watch.User
+		recipients[watch.UserID] = database.Notification{Type: notice.Type}
+	}
+
+	if mentions := Mentions(notice.Text); len(mentions) > 0 {
+		mentioned, err := database.FindUsersByUsernames(s.DB, mentions)
+		if err != nil {
+			return err
+		}
+
+		for _, user := range mentioned {
+			users[user.ID] = user
+			recipients[user.ID] = database.Notification{Type: database.NotificationMention}
+		}
+	}
+
+	for id, notification := range recipients {
+		user := users[id]
+		if id == notice.Actor.ID || user.Org || !notice.Repo.CanRead(s.DB, &user) {
+			continue
+		}
+
+		notification.UserID = id
+		notification.RepoID = notice.Repo.ID
+		notification.ActorID = notice.Actor.ID
+		notification.Title = notice.Title
+		notification.Link = notice.Link
+
+		if err := notification.Create(s.DB); err != nil {
+			return err
+		}
+
+		if s.Mailer == nil || !user.EmailNotifications || !user.EmailVerified {
+			continue
+		}
+
+		body := s.BaseURL + notice.Link + "\n"
+		if notice.Text != "" {
+			body = notice.Text + "\n\n" + body
+		}
+
+		msg := mail.Message{
+			To:      user.Email,
+			Subject: fmt.Sprintf("[%s] %s", path.Join(notice.Repo.User.Username, notice.Repo.Name), notice.Title),
+			Body:    body,
+		}
+
+		s.sendMail(&msg)
+	}
+
+	return nil
+}
@@ -0,0 +1,231 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/mail"
+)
+
+// testMailer records sent messages.
+type testMailer chan *mail.Message
+
+func (m testMailer) Send(msg *mail.Message) error {
+	m <- msg
+	return nil
+}
+
+func openTestDB(t *testing.T) *gorm.DB {
+	db, err := database.Open(sqlite.Open("file::memory:"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get database: %v", err)
+	}
+
+	// every connection would open a separate in-memory database
+	sqlDB.SetMaxOpenConns(1)
+
+	db.Logger = logger.Discard
+	return db
+}
+
+func createTestUser(t *testing.T, db *gorm.DB, username string) *database.User {
+	user := database.User{
+		Username:           username,
+		Email:              username + "@example.com",
+		EmailVerified:      true,
+		EmailNotifications: true,
+		Password:           "password",
+	}
+
+	if err := user.Create(db); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	return &user
+}
+
+func TestMentions(t *testing.T) {
+	tests := []struct {
+		text      string
+		usernames []string
+	}{
+		{"", nil},
+		{"@alice", []string{"alice"}},
+		{"cc @alice and @bob-smith, thanks @alice", []string{"alice", "bob-smith"}},
+		{"(@carol) @dave.", []string{"carol", "dave"}},
+		{"mail alice@example.com", nil},
+		{"see docs/@alice and a@b", nil},
+		{"@a is too short", nil},
+		{"@under_score_", []string{"under_score"}},
+	}
+
+	for _, test := range tests {
+		if usernames := Mentions(test.text); !reflect.DeepEqual(usernames, test.usernames) {
+			t.Errorf("Mentions(%q) = %q, want %q", test.text, usernames, test.usernames)
+		}
+	}
+}
+
+func TestNotify(t *testing.T) {
+	db := openTestDB(t)
+	mailer := make(testMailer, 10)
+
+	s := Server{
+		DB:      db,
+		Mailer:  mailer,
+		BaseURL: "https://multiverse.example.com",
+	}
+
+	alice := createTestUser(t, db, "alice")
+	reader := createTestUser(t, db, "reader")
+	watcher := createTestUser(t, db, "watcher")
+	stranger := createTestUser(t, db, "stranger")
+	unverified := createTestUser(t, db, "unverified")
+
+	unverified.EmailVerified = false
+	if err := unverified.UpdateEmailVerified(db); err != nil {
+		t.Fatalf("failed to update user: %v", err)
+	}
+
+	repo := database.Repo{Name: "secret", UserID: alice.ID, User: *alice, Visibility: database.RepoPrivate}
+	if err := repo.Create(db); err != nil {
+		t.Fatalf("failed to create repo: %v", err)
+	}
+
+	for _, user := range []*database.User{reader, unverified} {
+		collaborator := database.Collaborator{RepoID: repo.ID, UserID: user.ID, Role: database.RoleRead}
+		if err := collaborator.Save(db); err != nil {
+			t.Fatalf("failed to add collaborator: %v", err)
+		}
+	}
+
+	// the watcher lost access after watching
+	for _, user := range []*database.User{alice, watcher} {
+		watch := database.Watch{UserID: user.ID, RepoID: repo.ID}
+		if err := watch.Create(db); err != nil {
+			t.Fatalf("failed to watch repo: %v", err)
+		}
+	}
+
+	notice := Notice{
+		Actor: alice,
+		Repo:  &repo,
+		Type:  database.NotificationIssue,
+		Title: "Opened issue #1",
+		Link:  "/alice/secret/issues/1",
+		Text:  "@reader @stranger @unverified @alice please look",
+	}
+
+	if err := s.Notify(&notice); err != nil {
+		t.Fatalf("failed to notify: %v", err)
+	}
+
+	var notified []string
+	for _, user := range []*database.User{alice, reader, watcher, stranger, unverified} {
+		notifications, err := database.FindNotificationsByUserID(db, user.ID, true, 10)
+		if err != nil {
+			t.Fatalf("failed to find notifications: %v", err)
+		}
+
+		for _, n := range notifications {
+			if n.Type != database.NotificationMention || n.Link != notice.Link {
+				t.Errorf("unexpected notification of %s: %+v", user.Username, n)
+			}
+
+			notified = append(notified, user.Username)
+		}
+	}
+
+	if want := []string{"reader", "unverified"}; !reflect.DeepEqual(notified, want) {
+		t.Errorf("notified %q, want %q", notified, want)
+	}
+
+	var msg *mail.Message
+	select {
+	case msg = <-mailer:
+	case <-time.After(time.Second):
+		t.Fatal("no email was sent")
+	}
+
+	if msg.To != reader.Email {
+		t.Errorf("email sent to %q, want %q", msg.To, reader.Email)
+	}
+
+	if msg.Subject != "[alice/secret] Opened issue #1" {
+		t.Errorf("subject = %q", msg.Subject)
+	}
+
+	if !strings.HasSuffix(msg.Body, "\n\nhttps://multiverse.example.com/alice/secret/issues/1\n") {
+		t.Errorf("body = %q", msg.Body)
+	}
+
+	select {
+	case msg = <-mailer:
+		t.Errorf("unexpected email to %q", msg.To)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestNotifyWatchers(t *testing.T) {
+	db := openTestDB(t)
+	s := Server{DB: db}
+
+	alice := createTestUser(t, db, "alice")
+	bob := createTestUser(t, db, "bob")
+	carol := createTestUser(t, db, "carol")
+
+	repo := database.Repo{Name: "public", UserID: alice.ID, User: *alice}
+	if err := repo.Create(db); err != nil {
+		t.Fatalf("failed to create repo: %v", err)
+	}
+
+	for _, user := range []*database.User{alice, bob, carol} {
+		watch := database.Watch{UserID: user.ID, RepoID: repo.ID}
+		if err := watch.Create(db); err != nil {
+			t.Fatalf("failed to watch repo: %v", err)
+		}
+	}
+
+	notice := Notice{
+		Actor: bob,
+		Repo:  &repo,
+		Type:  database.NotificationPush,
+		Title: "Pushed to main",
+		Link:  "/alice/public/commits/main",
+		Text:  "@carol",
+	}
+
+	if err := s.Notify(&notice); err != nil {
+		t.Fatalf("failed to notify: %v", err)
+	}
+
+	types := make(map[string]string)
+	for _, user := range []*database.User{alice, bob, carol} {
+		notifications, err := database.FindNotificationsByUserID(db, user.ID, true, 10)
+		if err != nil {
+			t.Fatalf("failed to find notifications: %v", err)
+		}
+
+		for _, n := range notifications {
+			types[user.Username] = n.Type
+		}
+	}
+
+	// mentions take precedence over the watch notification type
+	want := map[string]string{"alice": database.NotificationPush, "carol": database.NotificationMention}
+	if !reflect.DeepEqual(types, want) {
+		t.Errorf("notification types = %v, want %v", types, want)
+	}
+}
@@ -0,0 +1,142 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	cid "github.com/ipfs/go-cid"
+	chunker "github.com/ipfs/go-ipfs-chunker"
+	pin "github.com/ipfs/go-ipfs-pinner"
+	"github.com/ipfs/go-unixfs/importer"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+)
+
+// ErrAssetExists is returned when adding an asset with a name already used by the release.
+var ErrAssetExists = errors.New("asset with the same name already exists")
+
+// AddReleaseAsset adds the file to IPFS, pins it and attaches it to the release.
+//
+// Callers must hold the pin lock.
+func (s *Server) AddReleaseAsset(ctx context.Context, release *database.Release, name string, r io.Reader) (*database.ReleaseAsset, error) {
+	var existing database.ReleaseAsset
+	if err := existing.FindByNameAndReleaseID(s.DB, name, release.ID); err == nil {
+		return nil, ErrAssetExists
+	}
+
+	counter := &countReader{r: r}
+	node, err := importer.BuildDagFromReader(s.Node.DAG, chunker.DefaultSplitter(counter))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Node.Pinning.Pin(ctx, node, true); err != nil {
+		return nil, err
+	}
+
+	asset := database.ReleaseAsset{
+		ReleaseID: release.ID,
+		Name:      name,
+		Size:      counter.n,
+		CID:       node.Cid().String(),
+	}
+
+	if err := asset.Save(s.DB); err != nil {
+		if err := s.unpinFile(ctx, asset.CID); err != nil {
+			return nil, err
+		}
+
+		return nil, err
+	}
+
+	return &asset, nil
+}
+
+// DeleteReleaseAsset removes the asset from its release and unpins it
+// unless other assets or avatars have the same content.
+//
+// Callers must hold the pin lock.
+func (s *Server) DeleteReleaseAsset(ctx context.Context, asset *database.ReleaseAsset) error {
+	if err := asset.Delete(s.DB); err != nil {
+		return err
+	}
+
+	return s.unpinFile(ctx, asset.CID)
+}
+
+// DeleteRelease deletes the release and unpins its assets.
+//
+// Callers must hold the pin lock.
+func (s *Server) DeleteRelease(ctx context.Context, release *database.Release) error {
+	if err := release.Delete(s.DB); err != nil {
+		return err
+	}
+
+	for _, asset := range release.Assets {
+		if err := s.unpinFile(ctx, asset.CID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteReleases deletes all releases of the repo and unpins their assets.
+//
+// Callers must hold the pin lock.
+func (s *Server) DeleteReleases(ctx context.Context, repo *database.Repo) error {
+	assets, err := database.FindReleaseAssetsByRepoID(s.DB, repo.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := database.DeleteReleasesByRepoID(s.DB, repo.ID); err != nil {
+		return err
+	}
+
+	for _, asset := range assets {
+		if err := s.unpinFile(ctx, asset.CID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unpinFile removes the pin for the given CID if no assets or avatars reference it.
+func (s *Server) unpinFile(ctx context.Context, id string) error {
+	assets, err := database.CountReleaseAssetsByCID(s.DB, id)
+	if err != nil || assets > 0 {
+		return err
+	}
+
+	avatars, err := database.CountUsersByAvatarCID(s.DB, id)
+	if err != nil || avatars > 0 {
+		return err
+	}
+
+	c, err := cid.Decode(id)
+	if err != nil {
+		return err
+	}
+
+	_, pinned, err := s.Node.Pinning.IsPinnedWithType(ctx, c, pin.Recursive)
+	if err != nil || !pinned {
+		return err
+	}
+
+	return s.Node.Pinning.Unpin(ctx, c, true)
+}
+
+// countReader counts the bytes read from the underlying reader.
+type countReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,171 @@
+package core
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+	cid "github.com/ipfs/go-cid"
+	"gorm.io/gorm"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/codesearch"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/gitutil"
+	"github.com/multiverse-vcs/go-git-ipfs/pkg/storage"
+	"github.com/multiverse-vcs/go-git-ipfs/pkg/storage/unixfs"
+)
+
+// CreateRepo initializes and pins an empty git repository for the repo
+// and creates it. Encrypted repos get a new key.
+//
+// Callers must hold the pin lock.
+func (s *Server) CreateRepo(ctx context.Context, repo *database.Repo) error {
+	var key []byte
+	if repo.Encrypted {
+		var err error
+		if key, err = storage.GenerateKey(); err != nil {
+			return err
+		}
+	}
+
+	node, err := gitutil.Init(ctx, s.Node.DAG, key)
+	if err != nil {
+		return err
+	}
+
+	repo.CID = node.Cid().String()
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := repo.Create(tx); err != nil {
+			return err
+		}
+
+		if key != nil {
+			if err := s.SaveRepoKey(tx, repo, key); err != nil {
+				return err
+			}
+		}
+
+		return s.Node.Pinning.Pin(ctx, node, true)
+	})
+}
+
+// ForkRepo creates a fork of the parent repo owned by the user.
+//
+// The fork points to the same content as the parent, so nothing is
+// copied or pinned. Forks of encrypted repos share the parent key.
+// The fork is indexed for code search in the background.
+func (s *Server) ForkRepo(parent *database.Repo, userID uint) (*database.Repo, error) {
+	key, err := s.RepoKey(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	fork := database.Repo{
+		Name:        parent.Name,
+		Description: parent.Description,
+		UserID:      userID,
+		CID:         parent.CID,
+		Visibility:  parent.Visibility,
+		Encrypted:   parent.Encrypted,
+		ParentID:    &parent.ID,
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := fork.Create(tx); err != nil {
+			return err
+		}
+
+		if key == nil {
+			return nil
+		}
+
+		return s.SaveRepoKey(tx, &fork, key)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// index in the background so forking is not delayed
+	go func(fork database.Repo) {
+		if err := codesearch.Index(context.Background(), s.DB, s.Node.DAG, &fork); err != nil {
+			log.Println(err)
+		}
+	}(fork)
+
+	return &fork, nil
+}
+
+// OpenRepo returns the git repository of the repo
+// decrypted with its key if it is encrypted.
+func (s *Server) OpenRepo(ctx context.Context, repo *database.Repo) (*git.Repository, error) {
+	key, err := s.RepoKey(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	return gitutil.Open(ctx, s.Node.DAG, repo.CID, key)
+}
+
+// LockRepo acquires the write lock of the repo with the given ID
+// and returns a function releasing it.
+//
+// Updates of the repo CID must hold the lock from loading the repo
+// until the new CID is stored, otherwise concurrent pushes, merges
+// and discussion updates overwrite each other. The pin lock only
+// keeps the garbage collector away and does not exclude writers.
+func (s *Server) LockRepo(id uint) func() {
+	lock, _ := s.repoLocks.LoadOrStore(id, new(sync.Mutex))
+	mutex := lock.(*sync.Mutex)
+	mutex.Lock()
+	return mutex.Unlock
+}
+
+// UpdateRepo opens the git repository of the repo for writing and calls
+// fn with it. The resulting content is pinned and becomes the repo CID.
+//
+// Callers must hold the pin lock and the repo lock, and must have
+// loaded the repo after acquiring the repo lock.
+func (s *Server) UpdateRepo(ctx context.Context, repo *database.Repo, fn func(*git.Repository) error) error {
+	key, err := s.RepoKey(repo)
+	if err != nil {
+		return err
+	}
+
+	id, err := cid.Decode(repo.CID)
+	if err != nil {
+		return err
+	}
+
+	fs, err := unixfs.Load(ctx, s.Node.DAG, id)
+	if err != nil {
+		return err
+	}
+
+	store, err := gitutil.NewStorage(fs, key)
+	if err != nil {
+		return err
+	}
+
+	git, err := git.Open(store, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(git); err != nil {
+		return err
+	}
+
+	node, err := fs.Node()
+	if err != nil {
+		return err
+	}
+
+	if err := s.Node.Pinning.Pin(ctx, node, true); err != nil {
+		return err
+	}
+
+	repo.CID = node.Cid().String()
+	return repo.UpdateCID(s.DB)
+}
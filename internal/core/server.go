@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sync"
 
 	config "github.com/ipfs/go-ipfs-config"
 	"github.com/ipfs/go-ipfs/core"
@@ -15,11 +16,22 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/mail"
 )
 
 type Server struct {
 	Node *core.IpfsNode
 	DB   *gorm.DB
+	// Key is used to wrap repository encryption keys and webhook secrets.
+	Key []byte
+	// Mailer sends notification emails. Emails are disabled if nil.
+	Mailer mail.Mailer
+	// BaseURL is the public URL of the server without a trailing slash.
+	// Links in emails are relative to it.
+	BaseURL string
+
+	// repoLocks contains a mutex for each repo ID, see LockRepo.
+	repoLocks sync.Map
 }
 
 // NewServer returns a new server.
@@ -32,6 +44,7 @@ func NewServer(ctx context.Context) (*Server, error) {
 	rpath := filepath.Join(home, ".multiverse")
 	ppath := filepath.Join(rpath, "plugins")
 	dpath := filepath.Join(rpath, "multiverse.db")
+	kpath := filepath.Join(rpath, "multiverse.key")
 
 	plugins, err := loader.NewPluginLoader(ppath)
 	if err != nil {
@@ -77,8 +90,21 @@ func NewServer(ctx context.Context) (*Server, error) {
 		return nil, err
 	}
 
+	key, err := loadKey(kpath)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := mail.BaseURLFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
 	return &Server{
-		Node: node,
-		DB:   db,
+		Node:    node,
+		DB:      db,
+		Key:     key,
+		Mailer:  mail.NewFromEnv(),
+		BaseURL: base,
 	}, nil
 }
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	chunker "github.com/ipfs/go-ipfs-chunker"
+	"github.com/ipfs/go-unixfs/importer"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+)
+
+// AvatarTypes contains the content types allowed for avatars.
+var AvatarTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp"}
+
+// ErrAvatarType is returned for avatars that are not images of an allowed type.
+var ErrAvatarType = errors.New("avatar must be a png, jpeg, gif or webp image")
+
+// SetAvatar adds the image to IPFS, pins it and sets it as the avatar of
+// the user. The previous avatar is unpinned unless other users share it.
+//
+// Callers must hold the pin lock.
+func (s *Server) SetAvatar(ctx context.Context, user *database.User, r io.Reader) error {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return err
+	}
+
+	if !IsAvatarType(http.DetectContentType(head[:n])) {
+		return ErrAvatarType
+	}
+
+	body := io.MultiReader(bytes.NewReader(head[:n]), r)
+	node, err := importer.BuildDagFromReader(s.Node.DAG, chunker.DefaultSplitter(body))
+	if err != nil {
+		return err
+	}
+
+	if err := s.Node.Pinning.Pin(ctx, node, true); err != nil {
+		return err
+	}
+
+	old := user.AvatarCID
+	user.AvatarCID = node.Cid().String()
+
+	if err := user.UpdateAvatarCID(s.DB); err != nil {
+		return err
+	}
+
+	if old == "" || old == user.AvatarCID {
+		return nil
+	}
+
+	return s.unpinFile(ctx, old)
+}
+
+// IsAvatarType returns true if the content type is allowed for avatars.
+func IsAvatarType(ctype string) bool {
+	for _, t := range AvatarTypes {
+		if ctype == t {
+			return true
+		}
+	}
+
+	return false
+}
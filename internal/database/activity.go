@@ -0,0 +1,92 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// ActivityPush activities update a ref of a repository.
+	ActivityPush = "push"
+	// ActivityCreate activities create a repository.
+	ActivityCreate = "create"
+	// ActivityFork activities create a repository by forking its parent.
+	ActivityFork = "fork"
+	// ActivityStar activities star a repository.
+	ActivityStar = "star"
+)
+
+// Activity is an action of a user on a repository.
+type Activity struct {
+	// UserID is the ID of the user performing the action.
+	UserID uint `gorm:"index"`
+	// User is the user performing the action.
+	User User
+	// RepoID is the repository ID.
+	RepoID uint `gorm:"index"`
+	// Repo is the repository.
+	Repo Repo
+	// Type is the kind of action.
+	Type string
+	// Ref is the full name of the updated ref of push activities.
+	Ref string
+	// Old is the hash the ref pointed to before the update.
+	Old string
+	// New is the hash the ref points to after the update.
+	New string
+	// Commits is the number of commits added to the ref.
+	Commits int
+
+	gorm.Model
+}
+
+func (a *Activity) Save(db *gorm.DB) error {
+	return db.Save(a).Error
+}
+
+// FindActivitiesByUserID returns the newest activities of the user
+// on repositories the viewer can read.
+func FindActivitiesByUserID(db *gorm.DB, userID uint, viewer *User, limit int) ([]Activity, error) {
+	visible := db.Session(&gorm.Session{NewDB: true}).
+		Model(&Repo{}).
+		Select("id").
+		Scopes(VisibleRepos(viewer))
+
+	var activities []Activity
+	err := db.Preload("User").Preload("Repo.User").Preload("Repo.Parent.User").
+		Where("user_id = ? AND repo_id IN (?)", userID, visible).
+		Order("created_at desc").
+		Limit(limit).
+		Find(&activities).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return activities, nil
+}
+
+// FindActivitiesSince returns the activities of the user since the given
+// time on repositories the viewer can read. Associations are not loaded.
+func FindActivitiesSince(db *gorm.DB, userID uint, viewer *User, since time.Time) ([]Activity, error) {
+	visible := db.Session(&gorm.Session{NewDB: true}).
+		Model(&Repo{}).
+		Select("id").
+		Scopes(VisibleRepos(viewer))
+
+	var activities []Activity
+	err := db.Where("user_id = ? AND repo_id IN (?) AND created_at >= ?", userID, visible, since).
+		Find(&activities).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return activities, nil
+}
+
+// DeleteActivitiesByRepoID removes the activities on the repository.
+func DeleteActivitiesByRepoID(db *gorm.DB, repoID uint) error {
+	return db.Unscoped().Where("repo_id = ?", repoID).Delete(&Activity{}).Error
+}
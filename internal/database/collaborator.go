@@ -0,0 +1,91 @@
+package database
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// RoleRead collaborators can read and clone the repository.
+	RoleRead = "read"
+	// RoleWrite collaborators can also push to the repository.
+	RoleWrite = "write"
+	// RoleMaintain collaborators can also edit the repository description.
+	RoleMaintain = "maintain"
+	// RoleAdmin collaborators can also change settings and manage collaborators.
+	RoleAdmin = "admin"
+	// RoleOwner is the role of the repository owner and cannot be granted.
+	RoleOwner = "owner"
+)
+
+// CollaboratorRoles contains the roles that can be granted to collaborators.
+var CollaboratorRoles = []string{RoleRead, RoleWrite, RoleMaintain, RoleAdmin}
+
+// roleLevels orders roles from least to most privileged.
+var roleLevels = map[string]int{
+	RoleRead:     1,
+	RoleWrite:    2,
+	RoleMaintain: 3,
+	RoleAdmin:    4,
+	RoleOwner:    5,
+}
+
+// HasRole returns true if the role is the minimum role or a more privileged one.
+func HasRole(role, min string) bool {
+	return roleLevels[role] >= roleLevels[min]
+}
+
+// Collaborator grants a user a role in a repository.
+type Collaborator struct {
+	// RepoID is the repository ID.
+	RepoID uint `gorm:"index:collaborator_repo_id_user_id,unique"`
+	// UserID is the collaborator's ID.
+	UserID uint `gorm:"index:collaborator_repo_id_user_id,unique"`
+	// User is the collaborator.
+	User User
+	// Role is the collaborator's role.
+	Role string
+
+	gorm.Model
+}
+
+// BeforeSave validates fields before saving.
+func (c *Collaborator) BeforeSave(tx *gorm.DB) error {
+	if roleLevels[c.Role] == 0 || c.Role == RoleOwner {
+		return errors.New("role must be read, write, maintain or admin")
+	}
+
+	return nil
+}
+
+func (c *Collaborator) Save(db *gorm.DB) error {
+	return db.Save(c).Error
+}
+
+func (c *Collaborator) Delete(db *gorm.DB) error {
+	return db.Unscoped().Delete(c).Error
+}
+
+func (c *Collaborator) FindByIDAndRepoID(db *gorm.DB, id interface{}, repoID uint) error {
+	return db.First(c, "id = ? AND repo_id = ?", id, repoID).Error
+}
+
+func (c *Collaborator) FindByRepoIDAndUserID(db *gorm.DB, repoID, userID uint) error {
+	return db.First(c, "repo_id = ? AND user_id = ?", repoID, userID).Error
+}
+
+// FindCollaboratorsByRepoID returns the collaborators of the repository.
+func FindCollaboratorsByRepoID(db *gorm.DB, repoID uint) ([]Collaborator, error) {
+	var collaborators []Collaborator
+	if err := db.Preload("User").Order("created_at").Find(&collaborators, "repo_id = ?", repoID).Error; err != nil {
+		return nil, err
+	}
+
+	return collaborators, nil
+}
+
+// DeleteCollaboratorsByRepoID removes all collaborators of the repository.
+func DeleteCollaboratorsByRepoID(db *gorm.DB, repoID uint) error {
+	return db.Unscoped().Where("repo_id = ?", repoID).Delete(&Collaborator{}).Error
+}
@@ -23,5 +23,113 @@ func Open(dialector gorm.Dialector) (*gorm.DB, error) {
 		return nil, err
 	}
 
+	if err := db.AutoMigrate(&Snapshot{}); err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&File{}); err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&Token{}); err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&UserToken{}); err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&Webhook{}); err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&Delivery{}); err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&Redirect{}); err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&RepoKey{}); err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&Collaborator{}); err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&Member{}); err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&Team{}); err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&TeamMember{}); err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&TeamRepo{}); err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&Transfer{}); err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&PullRequest{}); err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&PullComment{}); err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&PullReview{}); err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&Label{}); err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&Issue{}); err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&IssueComment{}); err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&Release{}); err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&ReleaseAsset{}); err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&Activity{}); err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&Star{}); err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&Watch{}); err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&Notification{}); err != nil {
+		return nil, err
+	}
+
+	if err := migrateSearch(db); err != nil {
+		return nil, err
+	}
+
 	return db, nil
 }
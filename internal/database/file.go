@@ -0,0 +1,26 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// File contains the contents of an indexed repository file.
+type File struct {
+	// RepoID is the repository ID.
+	RepoID uint `gorm:"index"`
+	// Repo is the repository the file belongs to.
+	Repo Repo
+	// Path is the file path relative to the repository root.
+	Path string
+	// Language is the name of the detected programming language.
+	Language string `gorm:"index"`
+	// Content is the text content of the file.
+	Content string
+
+	gorm.Model
+}
+
+// DeleteFilesByRepoID removes all indexed files of the repo.
+func DeleteFilesByRepoID(db *gorm.DB, repoID uint) error {
+	return db.Unscoped().Where("repo_id = ?", repoID).Delete(&File{}).Error
+}
@@ -0,0 +1,266 @@
+package database
+
+import (
+	"errors"
+	"regexp"
+
+	"gorm.io/gorm"
+)
+
+var labelNamePattern = regexp.MustCompile(`^[a-zA-Z0-9]+(?:[-_ ]?[a-zA-Z0-9]+)*$`)
+
+const (
+	// IssueOpen issues are unresolved.
+	IssueOpen = "open"
+	// IssueClosed issues are resolved.
+	IssueClosed = "closed"
+)
+
+// Issue is a bug report or task in a repository.
+type Issue struct {
+	// RepoID is the repository ID.
+	RepoID uint `gorm:"index:issue_repo_id_number,unique"`
+	// Number identifies the issue within its repository.
+	Number uint `gorm:"index:issue_repo_id_number,unique"`
+	// UserID is the author's ID.
+	UserID uint
+	// User is the author.
+	User User
+	// Title is a short summary of the issue.
+	Title string
+	// Body is the markdown description of the issue.
+	Body string
+	// State is open or closed.
+	State string `gorm:"default:open;index"`
+	// Labels categorize the issue.
+	Labels []Label `gorm:"many2many:issue_labels"`
+	// Assignees are the users working on the issue.
+	Assignees []User `gorm:"many2many:issue_assignees"`
+
+	gorm.Model
+}
+
+// BeforeSave validates fields before saving.
+func (i *Issue) BeforeSave(tx *gorm.DB) error {
+	if len(i.Title) == 0 || len(i.Title) > 256 {
+		return errors.New("title must be between 1 and 256 characters")
+	}
+
+	if len(i.Body) > 65536 {
+		return errors.New("body must be less than 65536 characters")
+	}
+
+	switch i.State {
+	case "":
+		i.State = IssueOpen
+	case IssueOpen, IssueClosed:
+	default:
+		return errors.New("state must be open or closed")
+	}
+
+	return nil
+}
+
+// Create creates the issue with the next number of its repository.
+func (i *Issue) Create(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var number uint
+		err := tx.Model(&Issue{}).
+			Select("COALESCE(MAX(number), 0)").
+			Where("repo_id = ?", i.RepoID).
+			Scan(&number).Error
+
+		if err != nil {
+			return err
+		}
+
+		i.Number = number + 1
+		return tx.Create(i).Error
+	})
+}
+
+func (i *Issue) Save(db *gorm.DB) error {
+	return db.Omit("Labels", "Assignees").Save(i).Error
+}
+
+// SetLabels replaces the labels of the issue.
+func (i *Issue) SetLabels(db *gorm.DB, labels []Label) error {
+	return db.Model(i).Association("Labels").Replace(labels)
+}
+
+// AddAssignee assigns the user to the issue.
+func (i *Issue) AddAssignee(db *gorm.DB, user *User) error {
+	return db.Model(i).Association("Assignees").Append(user)
+}
+
+// RemoveAssignee unassigns the user from the issue.
+func (i *Issue) RemoveAssignee(db *gorm.DB, user *User) error {
+	return db.Model(i).Association("Assignees").Delete(user)
+}
+
+// FindByNumberAndRepoID finds the issue and loads its author, labels and assignees.
+func (i *Issue) FindByNumberAndRepoID(db *gorm.DB, number interface{}, repoID uint) error {
+	return db.Preload("User").Preload("Labels").Preload("Assignees").
+		First(i, "number = ? AND repo_id = ?", number, repoID).Error
+}
+
+// FindIssuesByRepoID returns the issues of the repository in the given
+// state, newest first. Issues are limited to the label unless it is empty.
+func FindIssuesByRepoID(db *gorm.DB, repoID uint, state, label string) ([]Issue, error) {
+	query := db.Preload("User").Preload("Labels").Preload("Assignees").
+		Where("repo_id = ? AND state = ?", repoID, state)
+
+	if label != "" {
+		labeled := db.Session(&gorm.Session{NewDB: true}).
+			Table("issue_labels").
+			Select("issue_labels.issue_id").
+			Joins("JOIN labels ON labels.id = issue_labels.label_id").
+			Where("labels.repo_id = ? AND labels.name = ?", repoID, label)
+
+		query = query.Where("id IN (?)", labeled)
+	}
+
+	var issues []Issue
+	if err := query.Order("number desc").Find(&issues).Error; err != nil {
+		return nil, err
+	}
+
+	return issues, nil
+}
+
+// CloseIssuesByNumbers closes the open issues of the repository with the given numbers.
+func CloseIssuesByNumbers(db *gorm.DB, repoID uint, numbers []uint) error {
+	if len(numbers) == 0 {
+		return nil
+	}
+
+	return db.Model(&Issue{}).
+		Where("repo_id = ? AND state = ? AND number IN ?", repoID, IssueOpen, numbers).
+		UpdateColumn("state", IssueClosed).Error
+}
+
+// DeleteIssuesByRepoID removes the issues of the repository
+// with their comments, labels and assignees.
+func DeleteIssuesByRepoID(db *gorm.DB, repoID uint) error {
+	issues := db.Model(&Issue{}).Select("id").Where("repo_id = ?", repoID)
+	if err := db.Unscoped().Where("issue_id IN (?)", issues).Delete(&IssueComment{}).Error; err != nil {
+		return err
+	}
+
+	if err := db.Exec("DELETE FROM issue_labels WHERE issue_id IN (?)", issues).Error; err != nil {
+		return err
+	}
+
+	if err := db.Exec("DELETE FROM issue_assignees WHERE issue_id IN (?)", issues).Error; err != nil {
+		return err
+	}
+
+	if err := db.Unscoped().Where("repo_id = ?", repoID).Delete(&Issue{}).Error; err != nil {
+		return err
+	}
+
+	return db.Unscoped().Where("repo_id = ?", repoID).Delete(&Label{}).Error
+}
+
+// IssueComment is a comment on an issue.
+type IssueComment struct {
+	// IssueID is the issue ID.
+	IssueID uint `gorm:"index"`
+	// UserID is the author's ID.
+	UserID uint
+	// User is the author.
+	User User
+	// Body is the markdown comment text.
+	Body string
+
+	gorm.Model
+}
+
+// BeforeSave validates fields before saving.
+func (c *IssueComment) BeforeSave(tx *gorm.DB) error {
+	if len(c.Body) == 0 || len(c.Body) > 65536 {
+		return errors.New("comment must be between 1 and 65536 characters")
+	}
+
+	return nil
+}
+
+func (c *IssueComment) Save(db *gorm.DB) error {
+	return db.Save(c).Error
+}
+
+// FindIssueCommentsByIssueID returns the comments of the issue.
+func FindIssueCommentsByIssueID(db *gorm.DB, issueID uint) ([]IssueComment, error) {
+	var comments []IssueComment
+	if err := db.Preload("User").Order("created_at").Find(&comments, "issue_id = ?", issueID).Error; err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+// Label categorizes issues of a repository.
+type Label struct {
+	// RepoID is the repository ID.
+	RepoID uint `gorm:"index:label_repo_id_name,unique"`
+	// Name is the label name.
+	Name string `gorm:"index:label_repo_id_name,unique"`
+
+	gorm.Model
+}
+
+// BeforeSave validates fields before saving.
+func (l *Label) BeforeSave(tx *gorm.DB) error {
+	if len(l.Name) == 0 || len(l.Name) > 32 {
+		return errors.New("label must be between 1 and 32 characters")
+	}
+
+	if !labelNamePattern.MatchString(l.Name) {
+		return errors.New("label can only contain alphanumeric characters separated by _, - or spaces")
+	}
+
+	return nil
+}
+
+func (l *Label) Save(db *gorm.DB) error {
+	return db.Save(l).Error
+}
+
+// Delete removes the label from all issues and deletes it.
+func (l *Label) Delete(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM issue_labels WHERE label_id = ?", l.ID).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Delete(l).Error
+	})
+}
+
+func (l *Label) FindByIDAndRepoID(db *gorm.DB, id interface{}, repoID uint) error {
+	return db.First(l, "id = ? AND repo_id = ?", id, repoID).Error
+}
+
+// FindLabelsByRepoID returns the labels of the repository by name.
+func FindLabelsByRepoID(db *gorm.DB, repoID uint) ([]Label, error) {
+	var labels []Label
+	if err := db.Order("name").Find(&labels, "repo_id = ?", repoID).Error; err != nil {
+		return nil, err
+	}
+
+	return labels, nil
+}
+
+// FindLabelsByIDs returns the labels of the repository with the given IDs.
+func FindLabelsByIDs(db *gorm.DB, repoID uint, ids []string) ([]Label, error) {
+	var labels []Label
+	if len(ids) == 0 {
+		return labels, nil
+	}
+
+	if err := db.Find(&labels, "repo_id = ? AND id IN ?", repoID, ids).Error; err != nil {
+		return nil, err
+	}
+
+	return labels, nil
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+const (
+	// NotificationPush notifications are sent for pushes to watched repositories.
+	NotificationPush = "push"
+	// NotificationIssue notifications are sent for new issues in watched repositories.
+	NotificationIssue = "issue"
+	// NotificationPull notifications are sent for new pull requests in watched repositories.
+	NotificationPull = "pull"
+	// NotificationComment notifications are sent for comments and reviews in watched repositories.
+	NotificationComment = "comment"
+	// NotificationMention notifications are sent to users mentioned in issues, pull requests or comments.
+	NotificationMention = "mention"
+)
+
+// Notification informs a user of an event on a repository.
+type Notification struct {
+	// UserID is the ID of the notified user.
+	UserID uint `gorm:"index:notification_user_id_read"`
+	// RepoID is the repository ID.
+	RepoID uint `gorm:"index"`
+	// Repo is the repository the event occurred on.
+	Repo Repo
+	// ActorID is the ID of the user causing the event.
+	ActorID uint
+	// Actor is the user causing the event.
+	Actor User
+	// Type is the kind of event.
+	Type string
+	// Title is a summary of the event.
+	Title string
+	// Link is the path of the page showing the event.
+	Link string
+	// Read is true once the user has seen the notification.
+	Read bool `gorm:"index:notification_user_id_read"`
+
+	gorm.Model
+}
+
+func (n *Notification) Create(db *gorm.DB) error {
+	return db.Create(n).Error
+}
+
+func (n *Notification) FindByIDAndUserID(db *gorm.DB, id interface{}, userID uint) error {
+	return db.First(n, "id = ? AND user_id = ?", id, userID).Error
+}
+
+// MarkRead marks the notification as read.
+func (n *Notification) MarkRead(db *gorm.DB) error {
+	n.Read = true
+	return db.Model(n).UpdateColumn("read", true).Error
+}
+
+// FindNotificationsByUserID returns the newest notifications of the user.
+//
+// Read notifications are only included if all is true.
+func FindNotificationsByUserID(db *gorm.DB, userID uint, all bool, limit int) ([]Notification, error) {
+	tx := db.Preload("Repo.User").Preload("Actor").Where("user_id = ?", userID)
+	if !all {
+		tx = tx.Where("read = ?", false)
+	}
+
+	var notifications []Notification
+	if err := tx.Order("created_at desc").Limit(limit).Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+// CountUnreadNotifications returns the number of unread notifications of the user.
+func CountUnreadNotifications(db *gorm.DB, userID uint) (int64, error) {
+	var count int64
+	err := db.Model(&Notification{}).Where("user_id = ? AND read = ?", userID, false).Count(&count).Error
+	return count, err
+}
+
+// MarkNotificationsRead marks all notifications of the user as read.
+func MarkNotificationsRead(db *gorm.DB, userID uint) error {
+	return db.Model(&Notification{}).Where("user_id = ? AND read = ?", userID, false).UpdateColumn("read", true).Error
+}
+
+// DeleteNotificationsByRepoID removes the notifications of events on the repository.
+func DeleteNotificationsByRepoID(db *gorm.DB, repoID uint) error {
+	return db.Unscoped().Where("repo_id = ?", repoID).Delete(&Notification{}).Error
+}
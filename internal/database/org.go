@@ -0,0 +1,278 @@
+package database
+
+import (
+	"errors"
+	"regexp"
+
+	"gorm.io/gorm"
+)
+
+var teamNamePattern = regexp.MustCompile(`^[a-zA-Z0-9]+(?:[-_]?[a-zA-Z0-9]+)+$`)
+
+const (
+	// OrgMember members can see the organization and join teams.
+	OrgMember = "member"
+	// OrgOwner members can manage the organization and all of its repositories.
+	OrgOwner = "owner"
+)
+
+// OrgRoles contains the roles of organization members.
+var OrgRoles = []string{OrgMember, OrgOwner}
+
+// Member contains organization membership details.
+type Member struct {
+	// OrgID is the organization's ID.
+	OrgID uint `gorm:"index:member_org_id_user_id,unique"`
+	// UserID is the member's ID.
+	UserID uint `gorm:"index:member_org_id_user_id,unique"`
+	// User is the member.
+	User User
+	// Role is the member's role in the organization.
+	Role string
+
+	gorm.Model
+}
+
+// BeforeSave validates fields before saving.
+func (m *Member) BeforeSave(tx *gorm.DB) error {
+	if m.Role != OrgMember && m.Role != OrgOwner {
+		return errors.New("role must be member or owner")
+	}
+
+	return nil
+}
+
+func (m *Member) Save(db *gorm.DB) error {
+	return db.Save(m).Error
+}
+
+// Delete removes the member from the organization and its teams.
+func (m *Member) Delete(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		teams := tx.Model(&Team{}).Select("id").Where("org_id = ?", m.OrgID)
+		if err := tx.Unscoped().Where("user_id = ? AND team_id IN (?)", m.UserID, teams).Delete(&TeamMember{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Delete(m).Error
+	})
+}
+
+func (m *Member) FindByIDAndOrgID(db *gorm.DB, id interface{}, orgID uint) error {
+	return db.First(m, "id = ? AND org_id = ?", id, orgID).Error
+}
+
+func (m *Member) FindByOrgIDAndUserID(db *gorm.DB, orgID, userID uint) error {
+	return db.First(m, "org_id = ? AND user_id = ?", orgID, userID).Error
+}
+
+// IsOrgOwner returns true if the user is an owner of the organization.
+func IsOrgOwner(db *gorm.DB, orgID, userID uint) bool {
+	var member Member
+	if err := member.FindByOrgIDAndUserID(db, orgID, userID); err != nil {
+		return false
+	}
+
+	return member.Role == OrgOwner
+}
+
+// CreateOrg creates the organization and adds the user as its owner.
+func CreateOrg(db *gorm.DB, org *User, ownerID uint) error {
+	org.Org = true
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := org.Create(tx); err != nil {
+			return err
+		}
+
+		member := Member{
+			OrgID:  org.ID,
+			UserID: ownerID,
+			Role:   OrgOwner,
+		}
+
+		return member.Save(tx)
+	})
+}
+
+// CountOrgOwners returns the number of owners of the organization.
+func CountOrgOwners(db *gorm.DB, orgID uint) (int64, error) {
+	var count int64
+	err := db.Model(&Member{}).Where("org_id = ? AND role = ?", orgID, OrgOwner).Count(&count).Error
+	return count, err
+}
+
+// FindMembersByOrgID returns the members of the organization.
+func FindMembersByOrgID(db *gorm.DB, orgID uint) ([]Member, error) {
+	var members []Member
+	if err := db.Preload("User").Order("created_at").Find(&members, "org_id = ?", orgID).Error; err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// FindOrgsByUserID returns the organizations the user has the role in.
+//
+// An empty role matches all members.
+func FindOrgsByUserID(db *gorm.DB, userID uint, role string) ([]User, error) {
+	members := db.Model(&Member{}).Select("org_id").Where("user_id = ?", userID)
+	if role != "" {
+		members = members.Where("role = ?", role)
+	}
+
+	var orgs []User
+	if err := db.Order("username").Find(&orgs, "id IN (?)", members).Error; err != nil {
+		return nil, err
+	}
+
+	return orgs, nil
+}
+
+// Team grants organization members access to repositories.
+type Team struct {
+	// OrgID is the organization's ID.
+	OrgID uint `gorm:"index:team_org_id_name,unique"`
+	// Name is the team name.
+	Name string `gorm:"index:team_org_id_name,unique"`
+
+	gorm.Model
+}
+
+// BeforeSave validates fields before saving.
+func (t *Team) BeforeSave(tx *gorm.DB) error {
+	if len(t.Name) < 2 || len(t.Name) > 32 {
+		return errors.New("name must be between 2 and 32 characters")
+	}
+
+	if !teamNamePattern.MatchString(t.Name) {
+		return errors.New("name can only contain alphanumeric characters separated by _ or -")
+	}
+
+	return nil
+}
+
+func (t *Team) Create(db *gorm.DB) error {
+	return db.Create(t).Error
+}
+
+// Delete removes the team and its members and repository grants.
+func (t *Team) Delete(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("team_id = ?", t.ID).Delete(&TeamMember{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("team_id = ?", t.ID).Delete(&TeamRepo{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Delete(t).Error
+	})
+}
+
+func (t *Team) FindByNameAndOrgID(db *gorm.DB, name string, orgID uint) error {
+	return db.First(t, "name = ? AND org_id = ?", name, orgID).Error
+}
+
+// FindTeamsByOrgID returns the teams of the organization.
+func FindTeamsByOrgID(db *gorm.DB, orgID uint) ([]Team, error) {
+	var teams []Team
+	if err := db.Order("name").Find(&teams, "org_id = ?", orgID).Error; err != nil {
+		return nil, err
+	}
+
+	return teams, nil
+}
+
+// TeamMember adds an organization member to a team.
+type TeamMember struct {
+	// TeamID is the team's ID.
+	TeamID uint `gorm:"index:team_member_team_id_user_id,unique"`
+	// UserID is the member's ID.
+	UserID uint `gorm:"index:team_member_team_id_user_id,unique"`
+	// User is the member.
+	User User
+
+	gorm.Model
+}
+
+func (m *TeamMember) Create(db *gorm.DB) error {
+	return db.Create(m).Error
+}
+
+func (m *TeamMember) Delete(db *gorm.DB) error {
+	return db.Unscoped().Delete(m).Error
+}
+
+func (m *TeamMember) FindByIDAndTeamID(db *gorm.DB, id interface{}, teamID uint) error {
+	return db.First(m, "id = ? AND team_id = ?", id, teamID).Error
+}
+
+// FindTeamMembersByTeamID returns the members of the team.
+func FindTeamMembersByTeamID(db *gorm.DB, teamID uint) ([]TeamMember, error) {
+	var members []TeamMember
+	if err := db.Preload("User").Order("created_at").Find(&members, "team_id = ?", teamID).Error; err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// TeamRepo grants the members of a team a role in a repository.
+type TeamRepo struct {
+	// TeamID is the team's ID.
+	TeamID uint `gorm:"index:team_repo_team_id_repo_id,unique"`
+	// RepoID is the repository ID.
+	RepoID uint `gorm:"index:team_repo_team_id_repo_id,unique"`
+	// Repo is the repository.
+	Repo Repo
+	// Role is the role of team members in the repository.
+	Role string
+
+	gorm.Model
+}
+
+// BeforeSave validates fields before saving.
+func (r *TeamRepo) BeforeSave(tx *gorm.DB) error {
+	if roleLevels[r.Role] == 0 || r.Role == RoleOwner {
+		return errors.New("role must be read, write, maintain or admin")
+	}
+
+	return nil
+}
+
+func (r *TeamRepo) Save(db *gorm.DB) error {
+	return db.Save(r).Error
+}
+
+func (r *TeamRepo) Delete(db *gorm.DB) error {
+	return db.Unscoped().Delete(r).Error
+}
+
+func (r *TeamRepo) FindByIDAndTeamID(db *gorm.DB, id interface{}, teamID uint) error {
+	return db.First(r, "id = ? AND team_id = ?", id, teamID).Error
+}
+
+func (r *TeamRepo) FindByTeamIDAndRepoID(db *gorm.DB, teamID, repoID uint) error {
+	return db.First(r, "team_id = ? AND repo_id = ?", teamID, repoID).Error
+}
+
+// FindTeamReposByTeamID returns the repository grants of the team.
+func FindTeamReposByTeamID(db *gorm.DB, teamID uint) ([]TeamRepo, error) {
+	var repos []TeamRepo
+	if err := db.Preload("Repo").Order("created_at").Find(&repos, "team_id = ?", teamID).Error; err != nil {
+		return nil, err
+	}
+
+	return repos, nil
+}
+
+// teamRoles returns the roles granted to the user by teams for the repo.
+func teamRoles(db *gorm.DB, repoID, userID uint) ([]string, error) {
+	var roles []string
+	err := db.Model(&TeamRepo{}).
+		Joins("JOIN team_members ON team_members.team_id = team_repos.team_id AND team_members.deleted_at IS NULL").
+		Where("team_repos.repo_id = ? AND team_members.user_id = ?", repoID, userID).
+		Pluck("team_repos.role", &roles).Error
+	return roles, err
+}
@@ -0,0 +1,251 @@
+package database
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// PullOpen pull requests are waiting for review and merge.
+	PullOpen = "open"
+	// PullClosed pull requests were closed without merging.
+	PullClosed = "closed"
+	// PullMerged pull requests were merged into the base branch.
+	PullMerged = "merged"
+)
+
+const (
+	// ReviewComment reviews leave feedback without a verdict.
+	ReviewComment = "comment"
+	// ReviewApprove reviews approve the changes.
+	ReviewApprove = "approve"
+	// ReviewRequestChanges reviews ask for changes before merging.
+	ReviewRequestChanges = "request_changes"
+)
+
+// ReviewStates contains all review states.
+var ReviewStates = []string{ReviewComment, ReviewApprove, ReviewRequestChanges}
+
+// PullRequest proposes merging a branch into a branch of a repository.
+//
+// The head branch can be in the same repository or in a fork of it.
+type PullRequest struct {
+	// RepoID is the ID of the repository changes are merged into.
+	RepoID uint `gorm:"index:pull_request_repo_id_number,unique"`
+	// Number identifies the pull request within its repository.
+	Number uint `gorm:"index:pull_request_repo_id_number,unique"`
+	// UserID is the author's ID.
+	UserID uint
+	// User is the author.
+	User User
+	// Title is a short summary of the changes.
+	Title string
+	// Body describes the changes.
+	Body string
+	// BaseRef is the name of the branch changes are merged into.
+	BaseRef string
+	// HeadRepoID is the ID of the repository containing the changes.
+	HeadRepoID uint `gorm:"index"`
+	// HeadRepo is the repository containing the changes.
+	HeadRepo Repo
+	// HeadRef is the name of the branch containing the changes.
+	HeadRef string
+	// State is open, closed or merged.
+	State string `gorm:"default:open;index"`
+	// MergeBase is the hash of the merge base once merged.
+	MergeBase string
+	// HeadHash is the hash of the merged head commit once merged.
+	HeadHash string
+
+	gorm.Model
+}
+
+// BeforeSave validates fields before saving.
+func (p *PullRequest) BeforeSave(tx *gorm.DB) error {
+	if len(p.Title) == 0 || len(p.Title) > 256 {
+		return errors.New("title must be between 1 and 256 characters")
+	}
+
+	if len(p.Body) > 65536 {
+		return errors.New("body must be less than 65536 characters")
+	}
+
+	if p.BaseRef == "" || p.HeadRef == "" {
+		return errors.New("base and head branches are required")
+	}
+
+	if p.RepoID == p.HeadRepoID && p.BaseRef == p.HeadRef {
+		return errors.New("base and head branches must be different")
+	}
+
+	switch p.State {
+	case "":
+		p.State = PullOpen
+	case PullOpen, PullClosed, PullMerged:
+	default:
+		return errors.New("state must be open, closed or merged")
+	}
+
+	return nil
+}
+
+// Create creates the pull request with the next number of its repository.
+func (p *PullRequest) Create(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var number uint
+		err := tx.Model(&PullRequest{}).
+			Select("COALESCE(MAX(number), 0)").
+			Where("repo_id = ?", p.RepoID).
+			Scan(&number).Error
+
+		if err != nil {
+			return err
+		}
+
+		p.Number = number + 1
+		return tx.Create(p).Error
+	})
+}
+
+func (p *PullRequest) Save(db *gorm.DB) error {
+	return db.Save(p).Error
+}
+
+// FindByNumberAndRepoID finds the pull request and loads its
+// author and head repository.
+func (p *PullRequest) FindByNumberAndRepoID(db *gorm.DB, number interface{}, repoID uint) error {
+	return db.Preload("User").Preload("HeadRepo.User").First(p, "number = ? AND repo_id = ?", number, repoID).Error
+}
+
+// FindPullRequestsByRepoID returns the pull requests of the repository
+// in the given state, newest first.
+func FindPullRequestsByRepoID(db *gorm.DB, repoID uint, state string) ([]PullRequest, error) {
+	var pulls []PullRequest
+	err := db.Preload("User").Preload("HeadRepo.User").
+		Order("number desc").
+		Find(&pulls, "repo_id = ? AND state = ?", repoID, state).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return pulls, nil
+}
+
+// DeletePullRequestsByRepoID removes the pull requests into the repository
+// with their comments and reviews, and closes open pull requests from it.
+func DeletePullRequestsByRepoID(db *gorm.DB, repoID uint) error {
+	pulls := db.Model(&PullRequest{}).Select("id").Where("repo_id = ?", repoID)
+	if err := db.Unscoped().Where("pull_request_id IN (?)", pulls).Delete(&PullComment{}).Error; err != nil {
+		return err
+	}
+
+	if err := db.Unscoped().Where("pull_request_id IN (?)", pulls).Delete(&PullReview{}).Error; err != nil {
+		return err
+	}
+
+	if err := db.Unscoped().Where("repo_id = ?", repoID).Delete(&PullRequest{}).Error; err != nil {
+		return err
+	}
+
+	return db.Model(&PullRequest{}).
+		Where("head_repo_id = ? AND state = ?", repoID, PullOpen).
+		UpdateColumn("state", PullClosed).Error
+}
+
+// PullComment is a comment on a pull request.
+//
+// Comments with a path and line are attached to that line of the diff.
+type PullComment struct {
+	// PullRequestID is the pull request ID.
+	PullRequestID uint `gorm:"index"`
+	// UserID is the author's ID.
+	UserID uint
+	// User is the author.
+	User User
+	// Body is the comment text.
+	Body string
+	// Path is the file the comment is attached to.
+	Path string
+	// Line is the line in the head version of the file.
+	Line int
+
+	gorm.Model
+}
+
+// BeforeSave validates fields before saving.
+func (c *PullComment) BeforeSave(tx *gorm.DB) error {
+	if len(c.Body) == 0 || len(c.Body) > 65536 {
+		return errors.New("comment must be between 1 and 65536 characters")
+	}
+
+	if (c.Path == "") != (c.Line == 0) || c.Line < 0 {
+		return errors.New("line comments require a path and a positive line")
+	}
+
+	return nil
+}
+
+func (c *PullComment) Save(db *gorm.DB) error {
+	return db.Save(c).Error
+}
+
+// FindPullCommentsByPullRequestID returns the comments of the pull request.
+func FindPullCommentsByPullRequestID(db *gorm.DB, pullID uint) ([]PullComment, error) {
+	var comments []PullComment
+	if err := db.Preload("User").Order("created_at").Find(&comments, "pull_request_id = ?", pullID).Error; err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+// PullReview is a review of a pull request.
+type PullReview struct {
+	// PullRequestID is the pull request ID.
+	PullRequestID uint `gorm:"index"`
+	// UserID is the reviewer's ID.
+	UserID uint
+	// User is the reviewer.
+	User User
+	// State is comment, approve or request_changes.
+	State string
+	// Body is the review summary.
+	Body string
+
+	gorm.Model
+}
+
+// BeforeSave validates fields before saving.
+func (r *PullReview) BeforeSave(tx *gorm.DB) error {
+	switch r.State {
+	case ReviewComment, ReviewApprove, ReviewRequestChanges:
+	default:
+		return errors.New("state must be comment, approve or request_changes")
+	}
+
+	if len(r.Body) > 65536 {
+		return errors.New("body must be less than 65536 characters")
+	}
+
+	if r.State == ReviewComment && r.Body == "" {
+		return errors.New("comment reviews require a body")
+	}
+
+	return nil
+}
+
+func (r *PullReview) Save(db *gorm.DB) error {
+	return db.Save(r).Error
+}
+
+// FindPullReviewsByPullRequestID returns the reviews of the pull request.
+func FindPullReviewsByPullRequestID(db *gorm.DB, pullID uint) ([]PullReview, error) {
+	var reviews []PullReview
+	if err := db.Preload("User").Order("created_at").Find(&reviews, "pull_request_id = ?", pullID).Error; err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+}
@@ -0,0 +1,26 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// Redirect points a previous repository location to the repository.
+type Redirect struct {
+	// Username is the previous owner's username.
+	Username string `gorm:"index:username_name,unique"`
+	// Name is the previous repository name.
+	Name string `gorm:"index:username_name,unique"`
+	// RepoID is the repository ID.
+	RepoID uint `gorm:"index"`
+
+	gorm.Model
+}
+
+func (r *Redirect) FindByUsernameAndName(db *gorm.DB, username, name string) error {
+	return db.First(r, "username = ? AND name = ?", username, name).Error
+}
+
+// DeleteRedirectsByRepoID removes all redirects to the repo.
+func DeleteRedirectsByRepoID(db *gorm.DB, repoID uint) error {
+	return db.Unscoped().Where("repo_id = ?", repoID).Delete(&Redirect{}).Error
+}
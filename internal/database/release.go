@@ -0,0 +1,165 @@
+package database
+
+import (
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Release publishes a tag of a repository with notes and binary assets.
+type Release struct {
+	// RepoID is the repository ID.
+	RepoID uint `gorm:"index:release_repo_id_tag,unique"`
+	// Tag is the short name of the released tag.
+	Tag string `gorm:"index:release_repo_id_tag,unique"`
+	// UserID is the author's ID.
+	UserID uint
+	// User is the author.
+	User User
+	// Title is the name of the release.
+	Title string
+	// Notes are the markdown release notes.
+	Notes string
+	// Assets are the files attached to the release.
+	Assets []ReleaseAsset
+
+	gorm.Model
+}
+
+// BeforeSave validates fields before saving.
+func (r *Release) BeforeSave(tx *gorm.DB) error {
+	if r.Tag == "" {
+		return errors.New("tag is required")
+	}
+
+	if r.Title == "" {
+		r.Title = r.Tag
+	}
+
+	if len(r.Title) > 256 {
+		return errors.New("title must be less than 256 characters")
+	}
+
+	if len(r.Notes) > 65536 {
+		return errors.New("notes must be less than 65536 characters")
+	}
+
+	return nil
+}
+
+func (r *Release) Save(db *gorm.DB) error {
+	return db.Omit("Assets").Save(r).Error
+}
+
+// Delete deletes the release and its assets.
+//
+// Assets must be unpinned by the caller.
+func (r *Release) Delete(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("release_id = ?", r.ID).Delete(&ReleaseAsset{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Delete(r).Error
+	})
+}
+
+// FindByIDAndRepoID finds the release and loads its author and assets.
+func (r *Release) FindByIDAndRepoID(db *gorm.DB, id interface{}, repoID uint) error {
+	return db.Preload("User").Preload("Assets", orderAssets).
+		First(r, "id = ? AND repo_id = ?", id, repoID).Error
+}
+
+func (r *Release) FindByTagAndRepoID(db *gorm.DB, tag string, repoID uint) error {
+	return db.First(r, "tag = ? AND repo_id = ?", tag, repoID).Error
+}
+
+// FindReleasesByRepoID returns the releases of the repository newest first.
+func FindReleasesByRepoID(db *gorm.DB, repoID uint) ([]Release, error) {
+	var releases []Release
+	err := db.Preload("User").Preload("Assets", orderAssets).
+		Order("created_at desc").
+		Find(&releases, "repo_id = ?", repoID).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return releases, nil
+}
+
+// DeleteReleasesByRepoID removes the releases of the repository and their assets.
+//
+// Assets must be unpinned by the caller.
+func DeleteReleasesByRepoID(db *gorm.DB, repoID uint) error {
+	releases := db.Model(&Release{}).Select("id").Where("repo_id = ?", repoID)
+	if err := db.Unscoped().Where("release_id IN (?)", releases).Delete(&ReleaseAsset{}).Error; err != nil {
+		return err
+	}
+
+	return db.Unscoped().Where("repo_id = ?", repoID).Delete(&Release{}).Error
+}
+
+// ReleaseAsset is a file added to IPFS and attached to a release.
+type ReleaseAsset struct {
+	// ReleaseID is the release ID.
+	ReleaseID uint `gorm:"index:release_asset_release_id_name,unique"`
+	// Name is the file name.
+	Name string `gorm:"index:release_asset_release_id_name,unique"`
+	// Size is the file size in bytes.
+	Size int64
+	// CID is the content identifier of the pinned file.
+	CID string `gorm:"index"`
+
+	gorm.Model
+}
+
+// BeforeSave validates fields before saving.
+func (a *ReleaseAsset) BeforeSave(tx *gorm.DB) error {
+	if len(a.Name) == 0 || len(a.Name) > 256 {
+		return errors.New("asset name must be between 1 and 256 characters")
+	}
+
+	if a.Name == "." || a.Name == ".." || strings.ContainsAny(a.Name, "/\\?#") {
+		return errors.New("asset name cannot be a path or contain ? or #")
+	}
+
+	return nil
+}
+
+func (a *ReleaseAsset) Save(db *gorm.DB) error {
+	return db.Save(a).Error
+}
+
+func (a *ReleaseAsset) Delete(db *gorm.DB) error {
+	return db.Unscoped().Delete(a).Error
+}
+
+func (a *ReleaseAsset) FindByNameAndReleaseID(db *gorm.DB, name string, releaseID uint) error {
+	return db.First(a, "name = ? AND release_id = ?", name, releaseID).Error
+}
+
+// FindReleaseAssetsByRepoID returns the assets of all releases of the repository.
+func FindReleaseAssetsByRepoID(db *gorm.DB, repoID uint) ([]ReleaseAsset, error) {
+	releases := db.Model(&Release{}).Select("id").Where("repo_id = ?", repoID)
+
+	var assets []ReleaseAsset
+	if err := db.Find(&assets, "release_id IN (?)", releases).Error; err != nil {
+		return nil, err
+	}
+
+	return assets, nil
+}
+
+// CountReleaseAssetsByCID returns the number of assets with the given CID.
+func CountReleaseAssetsByCID(db *gorm.DB, id string) (int64, error) {
+	var count int64
+	err := db.Model(&ReleaseAsset{}).Where(&ReleaseAsset{CID: id}).Count(&count).Error
+	return count, err
+}
+
+// orderAssets sorts preloaded assets by name.
+func orderAssets(db *gorm.DB) *gorm.DB {
+	return db.Order("name")
+}
@@ -9,6 +9,15 @@ import (
 
 var repoNamePattern = regexp.MustCompile(`^[a-zA-Z0-9]+(?:[-_]?[a-zA-Z0-9]+)+$`)
 
+const (
+	// RepoPublic repositories are visible to everyone.
+	RepoPublic = "public"
+	// RepoInternal repositories are visible to logged in users.
+	RepoInternal = "internal"
+	// RepoPrivate repositories are only visible to the owner and collaborators.
+	RepoPrivate = "private"
+)
+
 // Repo contains repository info.
 type Repo struct {
 	// UserID is the owner's ID.
@@ -21,6 +30,18 @@ type Repo struct {
 	User User
 	// CID is the content identifier of the repository.
 	CID string
+	// Pages enables serving branches and tags as unixfs directories.
+	Pages bool
+	// Visibility controls who can read the repository.
+	Visibility string `gorm:"default:public;index"`
+	// Encrypted repositories store object and ref contents encrypted.
+	Encrypted bool
+	// ParentID is the ID of the repository this one was forked from.
+	ParentID *uint `gorm:"index"`
+	// Parent is the repository this one was forked from.
+	Parent *Repo
+	// Stars is the number of users who starred the repository.
+	Stars int `gorm:"default:0;not null"`
 
 	gorm.Model
 }
@@ -39,9 +60,91 @@ func (r *Repo) BeforeSave(tx *gorm.DB) error {
 		return errors.New("description must be less than 80 characters")
 	}
 
+	switch r.Visibility {
+	case "":
+		r.Visibility = RepoPublic
+	case RepoPublic, RepoInternal, RepoPrivate:
+	default:
+		return errors.New("visibility must be public, internal or private")
+	}
+
+	if r.Encrypted && r.Pages {
+		return errors.New("pages cannot be enabled for encrypted repositories")
+	}
+
 	return nil
 }
 
+// AfterSave removes redirects from the current location of the repo.
+func (r *Repo) AfterSave(tx *gorm.DB) error {
+	return tx.Unscoped().
+		Where("name = ? AND username IN (SELECT username FROM users WHERE id = ?)", r.Name, r.UserID).
+		Delete(&Redirect{}).Error
+}
+
+// Role returns the role of the user in the repo.
+//
+// Owners of the organization owning the repo are owners. Otherwise the
+// most privileged role granted by collaboration or teams is returned.
+// An empty role is returned if the user has no access.
+// A nil user is an anonymous visitor.
+func (r *Repo) Role(db *gorm.DB, user *User) string {
+	if user == nil {
+		return ""
+	}
+
+	if user.ID == r.UserID {
+		return RoleOwner
+	}
+
+	if IsOrgOwner(db, r.UserID, user.ID) {
+		return RoleOwner
+	}
+
+	var role string
+	var collaborator Collaborator
+	if err := collaborator.FindByRepoIDAndUserID(db, r.ID, user.ID); err == nil {
+		role = collaborator.Role
+	}
+
+	roles, err := teamRoles(db, r.ID, user.ID)
+	if err != nil {
+		return role
+	}
+
+	for _, other := range roles {
+		if roleLevels[other] > roleLevels[role] {
+			role = other
+		}
+	}
+
+	return role
+}
+
+// HasRole returns true if the user has the role or a more privileged one.
+func (r *Repo) HasRole(db *gorm.DB, user *User, role string) bool {
+	return HasRole(r.Role(db, user), role)
+}
+
+// CanRead returns true if the user can read the repo.
+//
+// A nil user is an anonymous visitor.
+func (r *Repo) CanRead(db *gorm.DB, user *User) bool {
+	switch r.Visibility {
+	case RepoInternal:
+		return user != nil
+	case RepoPrivate:
+		return r.HasRole(db, user, RoleRead)
+	default:
+		return true
+	}
+}
+
+// CanWrite returns true if the user can push to the repo.
+func (r *Repo) CanWrite(db *gorm.DB, user *User) bool {
+	return r.HasRole(db, user, RoleWrite)
+}
+
 func (r *Repo) Create(db *gorm.DB) error {
 	return db.Create(r).Error
 }
@@ -50,10 +153,194 @@ func (r *Repo) UpdateCID(db *gorm.DB) error {
 	return db.Model(r).Update("CID", r.CID).Error
 }
 
+func (r *Repo) UpdateDescription(db *gorm.DB) error {
+	return db.Model(r).Update("Description", r.Description).Error
+}
+
+func (r *Repo) UpdateVisibility(db *gorm.DB) error {
+	return db.Model(r).Update("Visibility", r.Visibility).Error
+}
+
+// Move renames the repo and transfers it to the user with the given ID.
+//
+// A redirect is created from the previous location of the repo.
+func (r *Repo) Move(db *gorm.DB, name string, userID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var owner User
+		if err := owner.Find(tx, r.UserID); err != nil {
+			return err
+		}
+
+		redirect := Redirect{
+			Username: owner.Username,
+			Name:     r.Name,
+			RepoID:   r.ID,
+		}
+
+		if err := tx.Create(&redirect).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&Webhook{}).Where("repo_id = ?", r.ID).UpdateColumn("user_id", userID).Error; err != nil {
+			return err
+		}
+
+		// the new owner no longer needs a collaborator role
+		if err := tx.Unscoped().Where("repo_id = ? AND user_id = ?", r.ID, userID).Delete(&Collaborator{}).Error; err != nil {
+			return err
+		}
+
+		// teams of the previous organization and pending transfers no longer apply
+		if userID != r.UserID {
+			if err := tx.Unscoped().Where("repo_id = ?", r.ID).Delete(&TeamRepo{}).Error; err != nil {
+				return err
+			}
+
+			if err := DeleteTransfersByRepoID(tx, r.ID); err != nil {
+				return err
+			}
+		}
+
+		// fields are validated before saving so they must be set first
+		prevName, prevUserID := r.Name, r.UserID
+		r.Name, r.UserID = name, userID
+
+		err := tx.Model(r).Updates(map[string]interface{}{"name": name, "user_id": userID}).Error
+		if err != nil {
+			r.Name, r.UserID = prevName, prevUserID
+		}
+
+		return err
+	})
+}
+
+// Delete permanently removes the repo and its redirects, indexed files,
+// snapshots, webhooks, collaborators, team grants, keys, pending
+// transfers, pull requests, issues, releases, activities, stars,
+// watches and notifications.
+//
+// Forks of the repo are detached from it and open pull requests
+// from it are closed.
+func (r *Repo) Delete(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := DeleteRedirectsByRepoID(tx, r.ID); err != nil {
+			return err
+		}
+
+		if err := DeleteFilesByRepoID(tx, r.ID); err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("repo_id = ?", r.ID).Delete(&Snapshot{}).Error; err != nil {
+			return err
+		}
+
+		hooks, err := FindWebhooksByRepoID(tx, r.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, hook := range hooks {
+			if err := hook.Delete(tx); err != nil {
+				return err
+			}
+		}
+
+		if err := DeleteCollaboratorsByRepoID(tx, r.ID); err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("repo_id = ?", r.ID).Delete(&TeamRepo{}).Error; err != nil {
+			return err
+		}
+
+		if err := DeleteRepoKeysByRepoID(tx, r.ID); err != nil {
+			return err
+		}
+
+		if err := DeleteTransfersByRepoID(tx, r.ID); err != nil {
+			return err
+		}
+
+		if err := DeletePullRequestsByRepoID(tx, r.ID); err != nil {
+			return err
+		}
+
+		if err := DeleteIssuesByRepoID(tx, r.ID); err != nil {
+			return err
+		}
+
+		if err := DeleteReleasesByRepoID(tx, r.ID); err != nil {
+			return err
+		}
+
+		if err := DeleteActivitiesByRepoID(tx, r.ID); err != nil {
+			return err
+		}
+
+		if err := DeleteStarsByRepoID(tx, r.ID); err != nil {
+			return err
+		}
+
+		if err := DeleteWatchesByRepoID(tx, r.ID); err != nil {
+			return err
+		}
+
+		if err := DeleteNotificationsByRepoID(tx, r.ID); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&Repo{}).Where("parent_id = ?", r.ID).UpdateColumn("parent_id", nil).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Delete(r).Error
+	})
+}
+
 func (r *Repo) Find(db *gorm.DB, id interface{}) error {
 	return db.First(r, id).Error
 }
 
+// FindByNameAndUserID finds the repo and loads its parent and parent owner.
 func (r *Repo) FindByNameAndUserID(db *gorm.DB, name string, userID uint) error {
-	return db.First(r, "name = ? AND user_id = ?", name, userID).Error
+	return db.Preload("Parent.User").First(r, "name = ? AND user_id = ?", name, userID).Error
+}
+
+// CountReposByCID returns the number of repos with the given CID.
+func CountReposByCID(db *gorm.DB, id string) (int64, error) {
+	var count int64
+	err := db.Model(&Repo{}).Where(&Repo{CID: id}).Count(&count).Error
+	return count, err
+}
+
+// FindForksByParentID returns the forks of the repo.
+func FindForksByParentID(db *gorm.DB, parentID uint) ([]Repo, error) {
+	var forks []Repo
+	if err := db.Preload("User").Order("created_at").Find(&forks, "parent_id = ?", parentID).Error; err != nil {
+		return nil, err
+	}
+
+	return forks, nil
+}
+
+// VisibleRepos returns a scope limiting repos to those the user can read.
+//
+// A nil user is an anonymous visitor.
+func VisibleRepos(user *User) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if user == nil {
+			return db.Where("visibility = ?", RepoPublic)
+		}
+
+		tx := db.Session(&gorm.Session{NewDB: true})
+		orgs := tx.Model(&Member{}).Select("org_id").Where("user_id = ? AND role = ?", user.ID, OrgOwner)
+		collaborations := tx.Model(&Collaborator{}).Select("repo_id").Where("user_id = ?", user.ID)
+		teams := tx.Model(&TeamRepo{}).Select("team_repos.repo_id").
+			Joins("JOIN team_members ON team_members.team_id = team_repos.team_id AND team_members.deleted_at IS NULL").
+			Where("team_members.user_id = ?", user.ID)
+
+		return db.Where("visibility IN ? OR user_id = ? OR user_id IN (?) OR id IN (?) OR id IN (?)",
+			[]string{RepoPublic, RepoInternal}, user.ID, orgs, collaborations, teams)
+	}
 }
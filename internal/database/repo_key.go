@@ -0,0 +1,28 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// RepoKey contains a wrapped repository encryption key.
+type RepoKey struct {
+	// RepoID is the repository ID.
+	RepoID uint `gorm:"uniqueIndex"`
+	// Key is the wrapped repository key.
+	Key []byte
+
+	gorm.Model
+}
+
+func (k *RepoKey) Create(db *gorm.DB) error {
+	return db.Create(k).Error
+}
+
+func (k *RepoKey) FindByRepoID(db *gorm.DB, repoID uint) error {
+	return db.First(k, "repo_id = ?", repoID).Error
+}
+
+// DeleteRepoKeysByRepoID removes the wrapped key of the repo.
+func DeleteRepoKeysByRepoID(db *gorm.DB, repoID uint) error {
+	return db.Unscoped().Where("repo_id = ?", repoID).Delete(&RepoKey{}).Error
+}
@@ -0,0 +1,137 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// searchSchema creates the full text search tables and the
+// triggers keeping them in sync with the repos and users tables.
+//
+// FTS4 is used because FTS5 is only available in sqlite builds
+// with the sqlite_fts5 tag. Triggers only fire on the indexed
+// columns so that frequent CID updates do not rewrite the index.
+var searchSchema = []string{
+	`CREATE VIRTUAL TABLE IF NOT EXISTS repo_search USING fts4(name, description, owner)`,
+	`CREATE VIRTUAL TABLE IF NOT EXISTS user_search USING fts4(username)`,
+	`CREATE TRIGGER IF NOT EXISTS repo_search_insert AFTER INSERT ON repos BEGIN
+		INSERT INTO repo_search(docid, name, description, owner)
+		SELECT new.id, new.name, new.description, users.username FROM users
+		WHERE users.id = new.user_id AND new.deleted_at IS NULL;
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS repo_search_update AFTER UPDATE OF name, description, user_id, deleted_at ON repos BEGIN
+		DELETE FROM repo_search WHERE docid = old.id;
+		INSERT INTO repo_search(docid, name, description, owner)
+		SELECT new.id, new.name, new.description, users.username FROM users
+		WHERE users.id = new.user_id AND new.deleted_at IS NULL;
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS repo_search_delete AFTER DELETE ON repos BEGIN
+		DELETE FROM repo_search WHERE docid = old.id;
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS user_search_insert AFTER INSERT ON users BEGIN
+		INSERT INTO user_search(docid, username)
+		SELECT new.id, new.username WHERE new.deleted_at IS NULL;
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS user_search_update AFTER UPDATE OF username, deleted_at ON users BEGIN
+		DELETE FROM user_search WHERE docid = old.id;
+		INSERT INTO user_search(docid, username)
+		SELECT new.id, new.username WHERE new.deleted_at IS NULL;
+		DELETE FROM repo_search WHERE docid IN (SELECT id FROM repos WHERE user_id = new.id);
+		INSERT INTO repo_search(docid, name, description, owner)
+		SELECT repos.id, repos.name, repos.description, new.username FROM repos
+		WHERE repos.user_id = new.id AND repos.deleted_at IS NULL AND new.deleted_at IS NULL;
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS user_search_delete AFTER DELETE ON users BEGIN
+		DELETE FROM user_search WHERE docid = old.id;
+	END`,
+	// rows created before the search tables existed are indexed once
+	`INSERT INTO repo_search(docid, name, description, owner)
+	SELECT repos.id, repos.name, repos.description, users.username FROM repos
+	JOIN users ON users.id = repos.user_id
+	WHERE repos.deleted_at IS NULL AND repos.id NOT IN (SELECT docid FROM repo_search)`,
+	`INSERT INTO user_search(docid, username)
+	SELECT id, username FROM users
+	WHERE deleted_at IS NULL AND id NOT IN (SELECT docid FROM user_search)`,
+}
+
+// RepoSortOrders maps sort options to repository orderings.
+//
+// Trending repos have the most stars within the trending period.
+var RepoSortOrders = map[string]string{
+	"updated":  "updated_at desc",
+	"created":  "created_at desc",
+	"stars":    "stars desc, updated_at desc",
+	"trending": "(SELECT COUNT(*) FROM stars WHERE stars.repo_id = repos.id AND stars.created_at >= @since) desc, stars desc",
+}
+
+// migrateSearch creates the full text search schema.
+func migrateSearch(db *gorm.DB) error {
+	for _, stmt := range searchSchema {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SearchRepos returns the repos whose name, description or owner match the text.
+//
+// All repos are returned when the text is empty.
+func SearchRepos(db *gorm.DB, text, sort string, offset, limit int) ([]Repo, error) {
+	order, ok := RepoSortOrders[sort]
+	if !ok {
+		order = RepoSortOrders["updated"]
+	}
+
+	since := sql.Named("since", time.Now().Add(-TrendingPeriod))
+	orderBy := clause.OrderBy{Expression: clause.NamedExpr{SQL: order, Vars: []interface{}{since}}}
+
+	tx := db.Preload("User").Preload("Parent.User").Clauses(orderBy).Offset(offset).Limit(limit)
+	if match := matchQuery(text); match != "" {
+		tx = tx.Where("id IN (SELECT docid FROM repo_search WHERE repo_search MATCH ?)", match)
+	}
+
+	var repos []Repo
+	if err := tx.Find(&repos).Error; err != nil {
+		return nil, err
+	}
+
+	return repos, nil
+}
+
+// SearchUsers returns the users whose username matches the text.
+//
+// All users are returned when the text is empty.
+func SearchUsers(db *gorm.DB, text string, offset, limit int) ([]User, error) {
+	tx := db.Order("username").Offset(offset).Limit(limit)
+	if match := matchQuery(text); match != "" {
+		tx = tx.Where("id IN (SELECT docid FROM user_search WHERE user_search MATCH ?)", match)
+	}
+
+	var users []User
+	if err := tx.Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// matchQuery returns a full text query matching all words of the
+// text as prefixes. Operators in the text are not interpreted.
+func matchQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, word := range words {
+		words[i] = word + "*"
+	}
+
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,162 @@
+package database
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	db, err := Open(sqlite.Open("file::memory:"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get database: %v", err)
+	}
+
+	// every connection would open a separate in-memory database
+	sqlDB.SetMaxOpenConns(1)
+
+	db.Logger = logger.Discard
+	return db
+}
+
+func createTestUser(t *testing.T, db *gorm.DB, username string) *User {
+	user := User{
+		Username: username,
+		Email:    username + "@example.com",
+		Password: "password",
+	}
+
+	if err := user.Create(db); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	return &user
+}
+
+func searchRepoNames(t *testing.T, db *gorm.DB, text string) []string {
+	repos, err := SearchRepos(db, text, "", 0, 10)
+	if err != nil {
+		t.Fatalf("failed to search repos: %v", err)
+	}
+
+	var names []string
+	for _, repo := range repos {
+		names = append(names, repo.User.Username+"/"+repo.Name)
+	}
+
+	return names
+}
+
+func TestMatchQuery(t *testing.T) {
+	tests := []struct {
+		text  string
+		match string
+	}{
+		{"", ""},
+		{"Hello", "hello*"},
+		{"go git", "go* git*"},
+		{"go-git-ipfs", "go* git* ipfs*"},
+		{`name OR "x" NEAR/2 -y *`, "name* or* x* near* 2* y*"},
+		{"über ünïcode", "über* ünïcode*"},
+	}
+
+	for _, test := range tests {
+		if match := matchQuery(test.text); match != test.match {
+			t.Errorf("matchQuery(%q) = %q, want %q", test.text, match, test.match)
+		}
+	}
+}
+
+func TestSearchReposTracksChanges(t *testing.T) {
+	db := openTestDB(t)
+
+	alice := createTestUser(t, db, "alice")
+	bob := createTestUser(t, db, "bob")
+
+	repo := Repo{Name: "widget", Description: "a useful tool", UserID: alice.ID}
+	if err := repo.Create(db); err != nil {
+		t.Fatalf("failed to create repo: %v", err)
+	}
+
+	steps := []struct {
+		name   string
+		change func() error
+		text   string
+		expect []string
+	}{
+		{"create", nil, "widget", []string{"alice/widget"}},
+		{"description", nil, "useful", []string{"alice/widget"}},
+		{"rename repo", func() error {
+			return repo.Move(db, "gadget", alice.ID)
+		}, "gadget", []string{"alice/gadget"}},
+		{"old repo name", nil, "widget", nil},
+		{"rename user", func() error {
+			return db.Model(alice).Update("username", "carol").Error
+		}, "carol", []string{"carol/gadget"}},
+		{"old username", nil, "alice", nil},
+		{"transfer", func() error {
+			return repo.Move(db, repo.Name, bob.ID)
+		}, "bob gadget", []string{"bob/gadget"}},
+		{"previous owner", nil, "carol", nil},
+		{"update cid", func() error {
+			repo.CID = "bafy"
+			return repo.UpdateCID(db)
+		}, "gadget", []string{"bob/gadget"}},
+		{"delete", func() error {
+			return repo.Delete(db)
+		}, "gadget", nil},
+	}
+
+	for _, step := range steps {
+		if step.change != nil {
+			if err := step.change(); err != nil {
+				t.Fatalf("%s: %v", step.name, err)
+			}
+		}
+
+		names := searchRepoNames(t, db, step.text)
+		if len(names) != len(step.expect) {
+			t.Fatalf("%s: search %q = %v, want %v", step.name, step.text, names, step.expect)
+		}
+
+		for i := range names {
+			if names[i] != step.expect[i] {
+				t.Fatalf("%s: search %q = %v, want %v", step.name, step.text, names, step.expect)
+			}
+		}
+	}
+}
+
+func TestSearchUsersTracksRename(t *testing.T) {
+	db := openTestDB(t)
+	alice := createTestUser(t, db, "alice")
+
+	if err := db.Model(alice).Update("username", "carol").Error; err != nil {
+		t.Fatalf("failed to rename user: %v", err)
+	}
+
+	users, err := SearchUsers(db, "car", 0, 10)
+	if err != nil {
+		t.Fatalf("failed to search users: %v", err)
+	}
+
+	if len(users) != 1 || users[0].Username != "carol" {
+		t.Fatalf("unexpected users %v", users)
+	}
+
+	users, err = SearchUsers(db, "alice", 0, 10)
+	if err != nil {
+		t.Fatalf("failed to search users: %v", err)
+	}
+
+	if len(users) != 0 {
+		t.Fatalf("unexpected users %v", users)
+	}
+}
@@ -1,6 +1,8 @@
 package database
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,6 +17,10 @@ type Session struct {
 	UserID uint `gorm:"index"`
 	// User is the session user.
 	User User
+	// UserAgent is the user agent that started the session.
+	UserAgent string
+	// ExpiresAt is the time the session can no longer be used.
+	ExpiresAt time.Time `gorm:"index"`
 	// CreatedAt is the time the session was created.
 	CreatedAt time.Time
 	// UpdatedAt is the time the session was updated.
@@ -27,10 +33,63 @@ func (s *Session) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// Viewer returns the session user or nil if there is no session.
+func (s *Session) Viewer() *User {
+	if s == nil {
+		return nil
+	}
+
+	return &s.User
+}
+
+// PublicID identifies the session without revealing its secret ID.
+func (s *Session) PublicID() string {
+	hash := sha256.Sum256([]byte(s.ID))
+	return hex.EncodeToString(hash[:8])
+}
+
 func (s *Session) Create(db *gorm.DB) error {
 	return db.Create(s).Error
 }
 
+// Renew extends the session until the given time.
+func (s *Session) Renew(db *gorm.DB, expiresAt time.Time) error {
+	s.ExpiresAt = expiresAt
+	s.UpdatedAt = time.Now()
+	return db.Model(s).UpdateColumns(map[string]interface{}{"expires_at": s.ExpiresAt, "updated_at": s.UpdatedAt}).Error
+}
+
+func (s *Session) Delete(db *gorm.DB) error {
+	return db.Delete(s).Error
+}
+
+// Find finds the unexpired session and loads its user.
 func (s *Session) Find(db *gorm.DB, id string) error {
-	return db.Preload("User").First(s, "id = ?", id).Error
+	return db.Preload("User").First(s, "id = ? AND expires_at > ?", id, time.Now()).Error
+}
+
+// FindSessionsByUserID returns the unexpired sessions of the user, most recently used first.
+func FindSessionsByUserID(db *gorm.DB, userID uint) ([]Session, error) {
+	var sessions []Session
+	err := db.Order("updated_at desc").Find(&sessions, "user_id = ? AND expires_at > ?", userID, time.Now()).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// DeleteSessionsByUserID removes all sessions of the user.
+func DeleteSessionsByUserID(db *gorm.DB, userID uint) error {
+	return db.Where("user_id = ?", userID).Delete(&Session{}).Error
+}
+
+// DeleteOtherSessions removes all sessions of the user except the one with the given ID.
+func DeleteOtherSessions(db *gorm.DB, userID uint, id string) error {
+	return db.Where("user_id = ? AND id <> ?", userID, id).Delete(&Session{}).Error
+}
+
+// DeleteExpiredSessions removes all sessions that can no longer be used.
+func DeleteExpiredSessions(db *gorm.DB) error {
+	return db.Where("expires_at <= ?", time.Now()).Delete(&Session{}).Error
 }
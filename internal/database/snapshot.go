@@ -0,0 +1,38 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// Snapshot contains the unixfs directory of a materialized ref.
+type Snapshot struct {
+	// RepoID is the repository ID.
+	RepoID uint `gorm:"index:name_repo_id,unique"`
+	// Name is the full name of the ref.
+	Name string `gorm:"index:name_repo_id,unique"`
+	// Hash is the commit hash the ref pointed to.
+	Hash string
+	// CID is the content identifier of the directory.
+	CID string
+
+	gorm.Model
+}
+
+func (s *Snapshot) Save(db *gorm.DB) error {
+	return db.Save(s).Error
+}
+
+func (s *Snapshot) Delete(db *gorm.DB) error {
+	return db.Unscoped().Delete(s).Error
+}
+
+func (s *Snapshot) FindByNameAndRepoID(db *gorm.DB, name string, repoID uint) error {
+	return db.First(s, "name = ? AND repo_id = ?", name, repoID).Error
+}
+
+// CountSnapshotsByCID returns the number of snapshots with the given CID.
+func CountSnapshotsByCID(db *gorm.DB, id string) (int64, error) {
+	var count int64
+	err := db.Model(&Snapshot{}).Where(&Snapshot{CID: id}).Count(&count).Error
+	return count, err
+}
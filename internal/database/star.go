@@ -0,0 +1,118 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TrendingPeriod is how far back stars count towards trending repos.
+const TrendingPeriod = 7 * 24 * time.Hour
+
+// Star is a bookmark of a repository by a user.
+type Star struct {
+	// UserID is the ID of the user starring the repository.
+	UserID uint `gorm:"index:star_user_id_repo_id,unique"`
+	// RepoID is the repository ID.
+	RepoID uint `gorm:"index:star_user_id_repo_id,unique;index"`
+	// Repo is the starred repository.
+	Repo Repo
+
+	gorm.Model
+}
+
+// Create stars the repo and increments its star count.
+func (s *Star) Create(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(s).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&Repo{}).Where("id = ?", s.RepoID).UpdateColumn("stars", gorm.Expr("stars + 1")).Error
+	})
+}
+
+// Delete permanently removes the star and decrements the repo star count.
+func (s *Star) Delete(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(s).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&Repo{}).Where("id = ?", s.RepoID).UpdateColumn("stars", gorm.Expr("stars - 1")).Error
+	})
+}
+
+func (s *Star) FindByUserIDAndRepoID(db *gorm.DB, userID, repoID uint) error {
+	return db.First(s, "user_id = ? AND repo_id = ?", userID, repoID).Error
+}
+
+// FindStarredRepos returns the repos starred by the user that the viewer
+// can read, most recently starred first.
+func FindStarredRepos(db *gorm.DB, userID uint, viewer *User) ([]Repo, error) {
+	var repos []Repo
+	err := db.Preload("User").Preload("Parent.User").
+		Scopes(VisibleRepos(viewer)).
+		Joins("JOIN stars ON stars.repo_id = repos.id").
+		Where("stars.user_id = ?", userID).
+		Order("stars.created_at desc").
+		Find(&repos).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return repos, nil
+}
+
+// DeleteStarsByRepoID removes the stars of the repository.
+func DeleteStarsByRepoID(db *gorm.DB, repoID uint) error {
+	return db.Unscoped().Where("repo_id = ?", repoID).Delete(&Star{}).Error
+}
+
+// Watch subscribes a user to the events of a repository.
+type Watch struct {
+	// UserID is the ID of the watching user.
+	UserID uint `gorm:"index:watch_user_id_repo_id,unique"`
+	// User is the watching user.
+	User User
+	// RepoID is the repository ID.
+	RepoID uint `gorm:"index:watch_user_id_repo_id,unique;index"`
+
+	gorm.Model
+}
+
+func (w *Watch) Create(db *gorm.DB) error {
+	return db.Create(w).Error
+}
+
+// Delete permanently removes the watch.
+func (w *Watch) Delete(db *gorm.DB) error {
+	return db.Unscoped().Delete(w).Error
+}
+
+func (w *Watch) FindByUserIDAndRepoID(db *gorm.DB, userID, repoID uint) error {
+	return db.First(w, "user_id = ? AND repo_id = ?", userID, repoID).Error
+}
+
+// FindWatchesByRepoID returns the watches of the repository and their users.
+func FindWatchesByRepoID(db *gorm.DB, repoID uint) ([]Watch, error) {
+	var watches []Watch
+	if err := db.Preload("User").Find(&watches, "repo_id = ?", repoID).Error; err != nil {
+		return nil, err
+	}
+
+	return watches, nil
+}
+
+// CountWatchesByRepoID returns the number of users watching the repository.
+func CountWatchesByRepoID(db *gorm.DB, repoID uint) (int64, error) {
+	var count int64
+	err := db.Model(&Watch{}).Where("repo_id = ?", repoID).Count(&count).Error
+	return count, err
+}
+
+// DeleteWatchesByRepoID removes the watches of the repository.
+func DeleteWatchesByRepoID(db *gorm.DB, repoID uint) error {
+	return db.Unscoped().Where("repo_id = ?", repoID).Delete(&Watch{}).Error
+}
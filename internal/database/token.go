@@ -0,0 +1,82 @@
+package database
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// tokenSize is the number of random bytes in a token secret.
+const tokenSize = 32
+
+// Token contains API access token details.
+type Token struct {
+	// UserID is the owner's ID.
+	UserID uint `gorm:"index"`
+	// User is the owner of the token.
+	User User
+	// Name is a description of the token.
+	Name string
+	// Secret is the plain text secret.
+	Secret string `gorm:"-"`
+	// SecretHash contains the hashed secret.
+	SecretHash string `gorm:"uniqueIndex"`
+
+	gorm.Model
+}
+
+// BeforeSave validates fields before saving.
+func (t *Token) BeforeSave(tx *gorm.DB) error {
+	if len(t.Name) < 1 || len(t.Name) > 32 {
+		return errors.New("name must be between 1 and 32 characters")
+	}
+
+	return nil
+}
+
+// BeforeCreate generates a new secret before creating.
+func (t *Token) BeforeCreate(tx *gorm.DB) error {
+	secret := make([]byte, tokenSize)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+
+	t.Secret = hex.EncodeToString(secret)
+	t.SecretHash = hashTokenSecret(t.Secret)
+	return nil
+}
+
+func (t *Token) Create(db *gorm.DB) error {
+	return db.Create(t).Error
+}
+
+func (t *Token) Delete(db *gorm.DB) error {
+	return db.Unscoped().Delete(t).Error
+}
+
+func (t *Token) FindBySecret(db *gorm.DB, secret string) error {
+	return db.Preload("User").First(t, "secret_hash = ?", hashTokenSecret(secret)).Error
+}
+
+func (t *Token) FindByIDAndUserID(db *gorm.DB, id interface{}, userID uint) error {
+	return db.First(t, "id = ? AND user_id = ?", id, userID).Error
+}
+
+// FindTokensByUserID returns the tokens owned by the user.
+func FindTokensByUserID(db *gorm.DB, userID uint) ([]Token, error) {
+	var tokens []Token
+	if err := db.Order("created_at desc").Find(&tokens, "user_id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// hashTokenSecret returns the hex encoded hash of the secret.
+func hashTokenSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// Transfer offers a repository to another user.
+//
+// The repository only changes owner once the user accepts the transfer.
+type Transfer struct {
+	// RepoID is the repository ID.
+	RepoID uint `gorm:"uniqueIndex"`
+	// Repo is the offered repository.
+	Repo Repo
+	// UserID is the ID of the user the repository is offered to.
+	UserID uint `gorm:"index"`
+	// User is the user the repository is offered to.
+	User User
+
+	gorm.Model
+}
+
+// Save creates the transfer or replaces the pending transfer of the repo.
+func (t *Transfer) Save(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := DeleteTransfersByRepoID(tx, t.RepoID); err != nil {
+			return err
+		}
+
+		return tx.Create(t).Error
+	})
+}
+
+func (t *Transfer) Delete(db *gorm.DB) error {
+	return db.Unscoped().Delete(t).Error
+}
+
+func (t *Transfer) FindByRepoID(db *gorm.DB, repoID uint) error {
+	return db.Preload("User").First(t, "repo_id = ?", repoID).Error
+}
+
+func (t *Transfer) FindByIDAndUserID(db *gorm.DB, id interface{}, userID uint) error {
+	return db.Preload("Repo").First(t, "id = ? AND user_id = ?", id, userID).Error
+}
+
+// FindTransfersByUserID returns the transfers offered to the user.
+func FindTransfersByUserID(db *gorm.DB, userID uint) ([]Transfer, error) {
+	var transfers []Transfer
+	if err := db.Preload("Repo.User").Order("created_at").Find(&transfers, "user_id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+
+	return transfers, nil
+}
+
+// DeleteTransfersByRepoID removes the pending transfer of the repo.
+func DeleteTransfersByRepoID(db *gorm.DB, repoID uint) error {
+	return db.Unscoped().Where("repo_id = ?", repoID).Delete(&Transfer{}).Error
+}
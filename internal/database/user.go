@@ -11,6 +11,7 @@ import (
 var (
 	userUsernamePattern = regexp.MustCompile(`^[a-zA-Z0-9]+(?:[-_]?[a-zA-Z0-9]+)+$`)
 	userEmailPattern    = regexp.MustCompile(`^[^@]+@[^\.]+\..+$`)
+	userWebsitePattern  = regexp.MustCompile(`^https?://[^\s/]+\S*$`)
 )
 
 // User contains account details.
@@ -19,10 +20,26 @@ type User struct {
 	Username string `gorm:"uniqueIndex"`
 	// Email is the account email address.
 	Email string `gorm:"uniqueIndex"`
+	// EmailVerified is true once the user confirmed the email address.
+	EmailVerified bool
 	// Password is the plain text password.
 	Password string `gorm:"-"`
 	// PasswordHash contains the hased password.
 	PasswordHash []byte
+	// Org is true if the account is an organization.
+	//
+	// Organizations have no password and cannot log in.
+	Org bool
+	// DisplayName is the full name shown on the profile.
+	DisplayName string
+	// Bio is a short description shown on the profile.
+	Bio string
+	// Website is the URL of the user's website.
+	Website string
+	// AvatarCID is the content identifier of the pinned avatar image.
+	AvatarCID string `gorm:"index"`
+	// EmailNotifications enables sending notifications by email.
+	EmailNotifications bool
 
 	gorm.Model
 }
@@ -41,16 +58,32 @@ func (u *User) BeforeSave(tx *gorm.DB) error {
 		return errors.New("email address format is invalid")
 	}
 
+	if len(u.DisplayName) > 64 {
+		return errors.New("display name must be less than 64 characters")
+	}
+
+	if len(u.Bio) > 256 {
+		return errors.New("bio must be less than 256 characters")
+	}
+
+	if u.Website != "" && !userWebsitePattern.MatchString(u.Website) {
+		return errors.New("website must be an http or https URL")
+	}
+
+	if len(u.Website) > 256 {
+		return errors.New("website must be less than 256 characters")
+	}
+
 	return nil
 }
 
 // BeforeCreate validates fields before creating.
 func (u *User) BeforeCreate(tx *gorm.DB) error {
-	if len(u.Password) < 8 || len(u.Password) > 64 {
-		return errors.New("password must be between 8 and 64 characters")
+	if u.Org {
+		return nil
 	}
 
-	hash, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+	hash, err := hashPassword(u.Password)
 	if err != nil {
 		return err
 	}
@@ -59,10 +92,48 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// CheckPassword returns an error if the password does not match.
+func (u *User) CheckPassword(password string) error {
+	return bcrypt.CompareHashAndPassword(u.PasswordHash, []byte(password))
+}
+
 func (u *User) Create(db *gorm.DB) error {
 	return db.Create(u).Error
 }
 
+// UpdatePassword hashes and saves the new password.
+func (u *User) UpdatePassword(db *gorm.DB, password string) error {
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	u.PasswordHash = hash
+	return db.Model(u).UpdateColumn("PasswordHash", u.PasswordHash).Error
+}
+
+// UpdateEmail saves the email address and marks it as unverified.
+func (u *User) UpdateEmail(db *gorm.DB) error {
+	u.EmailVerified = false
+	return db.Model(u).Select("Email", "EmailVerified").Updates(u).Error
+}
+
+func (u *User) UpdateEmailVerified(db *gorm.DB) error {
+	return db.Model(u).UpdateColumn("EmailVerified", u.EmailVerified).Error
+}
+
+func (u *User) UpdateProfile(db *gorm.DB) error {
+	return db.Model(u).Select("DisplayName", "Bio", "Website").Updates(u).Error
+}
+
+func (u *User) UpdateEmailNotifications(db *gorm.DB) error {
+	return db.Model(u).UpdateColumn("EmailNotifications", u.EmailNotifications).Error
+}
+
+func (u *User) UpdateAvatarCID(db *gorm.DB) error {
+	return db.Model(u).Update("AvatarCID", u.AvatarCID).Error
+}
+
 func (u *User) Find(db *gorm.DB, id interface{}) error {
 	return db.First(u, id).Error
 }
@@ -78,3 +149,29 @@ func (u *User) FindByUsername(db *gorm.DB, username string) error {
 func (u *User) FindByEmailOrUsername(db *gorm.DB, email, username string) error {
 	return db.First(u, "email = ? OR username = ?", email, username).Error
 }
+
+// CountUsersByAvatarCID returns the number of users with the given avatar CID.
+func CountUsersByAvatarCID(db *gorm.DB, id string) (int64, error) {
+	var count int64
+	err := db.Model(&User{}).Where(&User{AvatarCID: id}).Count(&count).Error
+	return count, err
+}
+
+// FindUsersByUsernames returns the users with the given usernames.
+func FindUsersByUsernames(db *gorm.DB, usernames []string) ([]User, error) {
+	var users []User
+	if err := db.Find(&users, "username IN ?", usernames).Error; err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// hashPassword validates the password and returns its bcrypt hash.
+func hashPassword(password string) ([]byte, error) {
+	if len(password) < 8 || len(password) > 64 {
+		return nil, errors.New("password must be between 8 and 64 characters")
+	}
+
+	return bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// TokenPasswordReset tokens allow setting a new password.
+	TokenPasswordReset = "password_reset"
+	// TokenVerifyEmail tokens confirm ownership of an email address.
+	TokenVerifyEmail = "verify_email"
+)
+
+// UserToken is a single use secret sent to a user by email.
+type UserToken struct {
+	// UserID is the owner's ID.
+	UserID uint `gorm:"index"`
+	// User is the owner of the token.
+	User User
+	// Purpose is the action the token allows.
+	Purpose string
+	// Email is the address the token was sent to.
+	Email string
+	// Secret is the plain text secret.
+	Secret string `gorm:"-"`
+	// SecretHash contains the hashed secret.
+	SecretHash string `gorm:"uniqueIndex"`
+	// ExpiresAt is the time the token can no longer be used.
+	ExpiresAt time.Time `gorm:"index"`
+
+	gorm.Model
+}
+
+// BeforeCreate generates a new secret before creating.
+func (t *UserToken) BeforeCreate(tx *gorm.DB) error {
+	secret := make([]byte, tokenSize)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+
+	t.Secret = hex.EncodeToString(secret)
+	t.SecretHash = hashTokenSecret(t.Secret)
+	return nil
+}
+
+func (t *UserToken) Create(db *gorm.DB) error {
+	return db.Create(t).Error
+}
+
+func (t *UserToken) Delete(db *gorm.DB) error {
+	return db.Unscoped().Delete(t).Error
+}
+
+// Consume deletes the token if it has not been used or expired yet.
+//
+// Only one of several concurrent callers deletes the token, the others
+// get gorm.ErrRecordNotFound, so each token can be used once.
+func (t *UserToken) Consume(db *gorm.DB) error {
+	res := db.Unscoped().Where("id = ? AND expires_at > ?", t.ID, time.Now()).Delete(&UserToken{})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected != 1 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// FindBySecretAndPurpose finds the unexpired token and loads its owner.
+func (t *UserToken) FindBySecretAndPurpose(db *gorm.DB, secret, purpose string) error {
+	return db.Preload("User").
+		First(t, "secret_hash = ? AND purpose = ? AND expires_at > ?", hashTokenSecret(secret), purpose, time.Now()).Error
+}
+
+// DeleteUserTokens removes the tokens of the user with the given purpose.
+func DeleteUserTokens(db *gorm.DB, userID uint, purpose string) error {
+	return db.Unscoped().Where("user_id = ? AND purpose = ?", userID, purpose).Delete(&UserToken{}).Error
+}
+
+// DeleteExpiredUserTokens removes all tokens that can no longer be used.
+func DeleteExpiredUserTokens(db *gorm.DB) error {
+	return db.Unscoped().Where("expires_at <= ?", time.Now()).Delete(&UserToken{}).Error
+}
@@ -0,0 +1,200 @@
+package database
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// WebhookPushEvent is sent when refs are pushed to a repository.
+	WebhookPushEvent = "push"
+	// WebhookCreateEvent is sent when a repository is created.
+	WebhookCreateEvent = "create"
+	// WebhookDeleteEvent is sent when branches are deleted by a push.
+	WebhookDeleteEvent = "delete"
+)
+
+// WebhookEvents contains all webhook event types.
+var WebhookEvents = []string{
+	WebhookPushEvent,
+	WebhookCreateEvent,
+	WebhookDeleteEvent,
+}
+
+// Webhook contains outgoing webhook configuration.
+//
+// Webhooks without a repository belong to the user and
+// receive events from all repositories the user owns.
+type Webhook struct {
+	// UserID is the owner's ID.
+	UserID uint `gorm:"index"`
+	// RepoID is the repository ID or zero for user webhooks.
+	RepoID uint `gorm:"index"`
+	// URL is the address payloads are posted to.
+	URL string
+	// Secret is the key used to sign payloads. It is not stored.
+	Secret string `gorm:"-"`
+	// SecretCiphertext contains the encrypted secret.
+	SecretCiphertext []byte
+	// Events is a comma separated list of event types.
+	Events string
+
+	gorm.Model
+}
+
+// BeforeSave validates fields before saving.
+func (h *Webhook) BeforeSave(tx *gorm.DB) error {
+	u, err := url.Parse(h.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be a valid http or https address")
+	}
+
+	if len(h.Secret) > 256 {
+		return errors.New("secret must be less than 256 characters")
+	}
+
+	if h.Events == "" {
+		return errors.New("at least one event must be selected")
+	}
+
+	for _, event := range strings.Split(h.Events, ",") {
+		if !isWebhookEvent(event) {
+			return errors.New("invalid event " + event)
+		}
+	}
+
+	return nil
+}
+
+// HasEvent returns true if the webhook is subscribed to the event.
+func (h *Webhook) HasEvent(event string) bool {
+	for _, e := range strings.Split(h.Events, ",") {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (h *Webhook) Create(db *gorm.DB) error {
+	return db.Create(h).Error
+}
+
+func (h *Webhook) Delete(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("webhook_id = ?", h.ID).Delete(&Delivery{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Delete(h).Error
+	})
+}
+
+func (h *Webhook) Find(db *gorm.DB, id interface{}) error {
+	return db.First(h, id).Error
+}
+
+func (h *Webhook) FindByIDAndRepoID(db *gorm.DB, id interface{}, repoID uint) error {
+	return db.First(h, "id = ? AND repo_id = ?", id, repoID).Error
+}
+
+func (h *Webhook) FindByIDAndUserID(db *gorm.DB, id interface{}, userID uint) error {
+	return db.First(h, "id = ? AND user_id = ? AND repo_id = 0", id, userID).Error
+}
+
+// FindWebhooksByRepoID returns the webhooks of the repository.
+func FindWebhooksByRepoID(db *gorm.DB, repoID uint) ([]Webhook, error) {
+	var hooks []Webhook
+	if err := db.Find(&hooks, "repo_id = ?", repoID).Error; err != nil {
+		return nil, err
+	}
+
+	return hooks, nil
+}
+
+// FindWebhooksByUserID returns the user webhooks of the user.
+func FindWebhooksByUserID(db *gorm.DB, userID uint) ([]Webhook, error) {
+	var hooks []Webhook
+	if err := db.Find(&hooks, "user_id = ? AND repo_id = 0", userID).Error; err != nil {
+		return nil, err
+	}
+
+	return hooks, nil
+}
+
+// FindWebhooksForEvent returns the webhooks subscribed to the event on the repository.
+func FindWebhooksForEvent(db *gorm.DB, repo *Repo, event string) ([]Webhook, error) {
+	var hooks []Webhook
+	if err := db.Find(&hooks, "repo_id = ? OR (user_id = ? AND repo_id = 0)", repo.ID, repo.UserID).Error; err != nil {
+		return nil, err
+	}
+
+	var res []Webhook
+	for _, h := range hooks {
+		if h.HasEvent(event) {
+			res = append(res, h)
+		}
+	}
+
+	return res, nil
+}
+
+// isWebhookEvent returns true if the event is a valid event type.
+func isWebhookEvent(event string) bool {
+	for _, e := range WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Delivery contains the result of sending a webhook payload.
+type Delivery struct {
+	// WebhookID is the webhook ID.
+	WebhookID uint `gorm:"index"`
+	// Event is the event type.
+	Event string
+	// Payload is the JSON payload.
+	Payload string
+	// Attempts is the number of delivery attempts.
+	Attempts int
+	// StatusCode is the response status of the last attempt.
+	StatusCode int
+	// Response is the start of the last response body.
+	Response string
+	// Error is the error of the last attempt.
+	Error string
+	// DeliveredAt is the time the payload was accepted.
+	DeliveredAt *time.Time
+
+	gorm.Model
+}
+
+func (d *Delivery) Create(db *gorm.DB) error {
+	return db.Create(d).Error
+}
+
+func (d *Delivery) Save(db *gorm.DB) error {
+	return db.Save(d).Error
+}
+
+func (d *Delivery) FindByIDAndWebhookID(db *gorm.DB, id interface{}, webhookID uint) error {
+	return db.First(d, "id = ? AND webhook_id = ?", id, webhookID).Error
+}
+
+// FindDeliveriesByWebhookID returns the most recent deliveries of the webhook.
+func FindDeliveriesByWebhookID(db *gorm.DB, webhookID uint, limit int) ([]Delivery, error) {
+	var deliveries []Delivery
+	if err := db.Order("created_at desc").Limit(limit).Find(&deliveries, "webhook_id = ?", webhookID).Error; err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
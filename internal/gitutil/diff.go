@@ -0,0 +1,157 @@
+package gitutil
+
+import (
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// DiffContext is the number of unchanged lines shown around changes.
+const DiffContext = 3
+
+const (
+	// LineEqual lines are unchanged.
+	LineEqual = "equal"
+	// LineAdd lines are added.
+	LineAdd = "add"
+	// LineDelete lines are deleted.
+	LineDelete = "delete"
+)
+
+// DiffLine is a line of a file diff.
+//
+// Old and New are line numbers in the old and new file
+// and zero for added and deleted lines respectively.
+type DiffLine struct {
+	Type string
+	Old  int
+	New  int
+	Text string
+}
+
+// DiffHunk is a group of changed lines with their context.
+type DiffHunk struct {
+	Lines []DiffLine
+}
+
+// FileDiff contains the changes to a file.
+//
+// From is empty for added files and To is empty for deleted files.
+type FileDiff struct {
+	From   string
+	To     string
+	Binary bool
+	Hunks  []DiffHunk
+}
+
+// Path returns the path of the file after the change,
+// or before the change if the file was deleted.
+func (f FileDiff) Path() string {
+	if f.To != "" {
+		return f.To
+	}
+
+	return f.From
+}
+
+// Diff returns the changes between the trees of the given commits.
+func Diff(s storer.EncodedObjectStorer, from, to plumbing.Hash) ([]FileDiff, error) {
+	a, err := object.GetCommit(s, from)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := object.GetCommit(s, to)
+	if err != nil {
+		return nil, err
+	}
+
+	patch, err := a.Patch(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []FileDiff
+	for _, fp := range patch.FilePatches() {
+		var fd FileDiff
+
+		from, to := fp.Files()
+		if from != nil {
+			fd.From = from.Path()
+		}
+
+		if to != nil {
+			fd.To = to.Path()
+		}
+
+		fd.Binary = fp.IsBinary()
+		fd.Hunks = hunks(fp.Chunks())
+		diffs = append(diffs, fd)
+	}
+
+	return diffs, nil
+}
+
+// hunks splits the chunks into numbered lines and groups
+// changes with up to DiffContext unchanged lines around them.
+func hunks(chunks []diff.Chunk) []DiffHunk {
+	var lines []DiffLine
+	var o, n int
+
+	for _, chunk := range chunks {
+		text := strings.TrimSuffix(chunk.Content(), "\n")
+		for _, t := range strings.Split(text, "\n") {
+			line := DiffLine{Text: t}
+
+			switch chunk.Type() {
+			case diff.Equal:
+				o++
+				n++
+				line.Type, line.Old, line.New = LineEqual, o, n
+			case diff.Add:
+				n++
+				line.Type, line.New = LineAdd, n
+			case diff.Delete:
+				o++
+				line.Type, line.Old = LineDelete, o
+			}
+
+			lines = append(lines, line)
+		}
+	}
+
+	// mark lines within the context of a change
+	show := make([]bool, len(lines))
+	for i, line := range lines {
+		if line.Type == LineEqual {
+			continue
+		}
+
+		for j := i - DiffContext; j <= i+DiffContext; j++ {
+			if j >= 0 && j < len(lines) {
+				show[j] = true
+			}
+		}
+	}
+
+	var result []DiffHunk
+	var hunk *DiffHunk
+	for i, line := range lines {
+		if !show[i] {
+			hunk = nil
+			continue
+		}
+
+		if hunk == nil {
+			result = append(result, DiffHunk{})
+			hunk = &result[len(result)-1]
+		}
+
+		hunk.Lines = append(hunk.Lines, line)
+	}
+
+	return result
+}
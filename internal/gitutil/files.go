@@ -0,0 +1,125 @@
+package gitutil
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// CommitFiles commits a tree containing only the given files to the ref
+// and returns the hash of the commit. Files are keyed by slash separated
+// paths.
+//
+// The previous commit of the ref is the parent. If the tree is unchanged
+// no commit is created and the previous commit is returned.
+func CommitFiles(repo *git.Repository, name plumbing.ReferenceName, files map[string][]byte, sig object.Signature, message string) (plumbing.Hash, error) {
+	tree, err := writeTree(repo.Storer, files)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	commit := object.Commit{
+		Author:    sig,
+		Committer: sig,
+		Message:   message,
+		TreeHash:  tree,
+	}
+
+	ref, err := repo.Reference(name, true)
+	switch err {
+	case nil:
+		parent, err := repo.CommitObject(ref.Hash())
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+
+		if parent.TreeHash == tree {
+			return parent.Hash, nil
+		}
+
+		commit.ParentHashes = []plumbing.Hash{parent.Hash}
+	case plumbing.ErrReferenceNotFound:
+	default:
+		return plumbing.ZeroHash, err
+	}
+
+	obj := repo.Storer.NewEncodedObject()
+	if err := commit.Encode(obj); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	hash, err := repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	return hash, repo.Storer.SetReference(plumbing.NewHashReference(name, hash))
+}
+
+// writeTree writes the files as blobs and nested trees
+// and returns the hash of the root tree.
+func writeTree(s storer.EncodedObjectStorer, files map[string][]byte) (plumbing.Hash, error) {
+	dirs := make(map[string]map[string][]byte)
+
+	var tree object.Tree
+	for p, data := range files {
+		parts := strings.SplitN(p, "/", 2)
+		if len(parts) == 2 {
+			if dirs[parts[0]] == nil {
+				dirs[parts[0]] = make(map[string][]byte)
+			}
+
+			dirs[parts[0]][parts[1]] = data
+			continue
+		}
+
+		obj := s.NewEncodedObject()
+		obj.SetType(plumbing.BlobObject)
+		obj.SetSize(int64(len(data)))
+
+		w, err := obj.Writer()
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+
+		if _, err := w.Write(data); err != nil {
+			return plumbing.ZeroHash, err
+		}
+
+		if err := w.Close(); err != nil {
+			return plumbing.ZeroHash, err
+		}
+
+		hash, err := s.SetEncodedObject(obj)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+
+		tree.Entries = append(tree.Entries, object.TreeEntry{Name: p, Mode: filemode.Regular, Hash: hash})
+	}
+
+	for name, children := range dirs {
+		hash, err := writeTree(s, children)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+
+		tree.Entries = append(tree.Entries, object.TreeEntry{Name: name, Mode: filemode.Dir, Hash: hash})
+	}
+
+	sort.Slice(tree.Entries, func(i, j int) bool {
+		return sortName(tree.Entries[i]) < sortName(tree.Entries[j])
+	})
+
+	obj := s.NewEncodedObject()
+	if err := tree.Encode(obj); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	return s.SetEncodedObject(obj)
+}
@@ -2,7 +2,6 @@ package gitutil
 
 import (
 	"context"
-	"errors"
 	"regexp"
 	"strings"
 
@@ -18,14 +17,30 @@ import (
 
 var readme = regexp.MustCompile(`(?i)^read\s*me(\..*)?$`)
 
+// NewStorage returns a storer for the unixfs directory.
+//
+// Contents are encrypted with the key unless it is nil.
+func NewStorage(fs *unixfs.Unixfs, key []byte) (*storage.Storage, error) {
+	if key == nil {
+		return storage.NewStorage(fs), nil
+	}
+
+	return storage.NewEncryptedStorage(fs, key)
+}
+
 // Init initializes a new repository and returns its unixfs node.
-func Init(ctx context.Context, ds ipld.DAGService) (ipld.Node, error) {
+func Init(ctx context.Context, ds ipld.DAGService, key []byte) (ipld.Node, error) {
 	fs, err := unixfs.New(ctx, ds)
 	if err != nil {
 		return nil, err
 	}
 
-	if _, err = git.Init(storage.NewStorage(fs), nil); err != nil {
+	store, err := NewStorage(fs, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = git.Init(store, nil); err != nil {
 		return nil, err
 	}
 
@@ -33,7 +48,7 @@ func Init(ctx context.Context, ds ipld.DAGService) (ipld.Node, error) {
 }
 
 // Open returns the git repository with the given CID.
-func Open(ctx context.Context, ds ipld.DAGService, id string) (*git.Repository, error) {
+func Open(ctx context.Context, ds ipld.DAGService, id string, key []byte) (*git.Repository, error) {
 	c, err := cid.Decode(id)
 	if err != nil {
 		return nil, err
@@ -44,7 +59,12 @@ func Open(ctx context.Context, ds ipld.DAGService, id string) (*git.Repository,
 		return nil, err
 	}
 
-	return git.Open(storage.NewStorage(fs), nil)
+	store, err := NewStorage(fs, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return git.Open(store, nil)
 }
 
 // HeadOrDefault returns the head or default branch for the repo.
@@ -98,10 +118,15 @@ func Tags(repo *git.Repository) ([]*plumbing.Reference, error) {
 	return tags, err
 }
 
-// Logs returns a list of commits from the repo head.
+// Logs returns a list of commits from the given ref.
 func Logs(repo *git.Repository, ref *plumbing.Reference, offset, max int) ([]*object.Commit, error) {
+	commit, err := Commit(repo, ref)
+	if err != nil {
+		return nil, err
+	}
+
 	opts := git.LogOptions{
-		From:  ref.Hash(),
+		From:  commit.Hash,
 		Order: git.LogOrderCommitterTime,
 	}
 
@@ -125,7 +150,66 @@ func Logs(repo *git.Repository, ref *plumbing.Reference, offset, max int) ([]*ob
 	return commits, err
 }
 
+// Commit returns the commit the given reference points to.
+func Commit(repo *git.Repository, ref *plumbing.Reference) (*object.Commit, error) {
+	if ref.Type() == plumbing.SymbolicReference {
+		res, err := repo.Reference(ref.Name(), true)
+		if err != nil {
+			return nil, err
+		}
+
+		ref = res
+	}
+
+	tag, err := repo.TagObject(ref.Hash())
+	switch err {
+	case nil:
+		return tag.Commit()
+	case plumbing.ErrObjectNotFound:
+		return repo.CommitObject(ref.Hash())
+	default:
+		return nil, err
+	}
+}
+
+// Resolve returns the reference with the given name.
+//
+// The name can be a full reference name, a short branch
+// or tag name, or a commit hash.
+func Resolve(repo *git.Repository, name string) (*plumbing.Reference, error) {
+	names := []plumbing.ReferenceName{
+		plumbing.ReferenceName(name),
+		plumbing.NewBranchReferenceName(name),
+		plumbing.NewTagReferenceName(name),
+	}
+
+	for _, n := range names {
+		ref, err := repo.Reference(n, false)
+		if err == nil {
+			return ref, nil
+		}
+
+		if err != plumbing.ErrReferenceNotFound {
+			return nil, err
+		}
+	}
+
+	if !plumbing.IsHash(name) {
+		return nil, plumbing.ErrReferenceNotFound
+	}
+
+	hash := plumbing.NewHash(name)
+	if _, err := repo.CommitObject(hash); err != nil {
+		return nil, err
+	}
+
+	return plumbing.NewHashReference(plumbing.ReferenceName(name), hash), nil
+}
+
 // RefPath splits a path into the ref and path parts.
+//
+// The ref part can be a full reference name or a commit hash.
+// plumbing.ErrReferenceNotFound is returned if it is neither.
 func RefPath(repo *git.Repository, path string) (*plumbing.Reference, string, error) {
 	iter, err := repo.References()
 	if err != nil {
@@ -134,29 +218,36 @@ func RefPath(repo *git.Repository, path string) (*plumbing.Reference, string, er
 
 	var ref *plumbing.Reference
 	err = iter.ForEach(func(r *plumbing.Reference) error {
-		if strings.HasPrefix(path, r.Name().String()) {
+		name := r.Name().String()
+		if path != name && !strings.HasPrefix(path, name+"/") {
+			return nil
+		}
+
+		if ref == nil || len(ref.Name()) < len(name) {
 			ref = r
 		}
 
 		return nil
 	})
 
+	if err != nil {
+		return nil, "", err
+	}
+
 	if ref == nil {
-		return nil, "", errors.New("invalid ref path")
+		parts := strings.SplitN(path, "/", 2)
+		if ref, err = Resolve(repo, parts[0]); err != nil || ref.Type() != plumbing.HashReference {
+			return nil, "", plumbing.ErrReferenceNotFound
+		}
 	}
 
 	path = strings.TrimPrefix(path, ref.Name().String())
-	return ref, path, err
+	return ref, path, nil
 }
 
 // Find returns a tree or blob from the given repo at the given ref and path.
 func Find(repo *git.Repository, ref *plumbing.Reference, path string) (object.Object, error) {
-	res, err := repo.Reference(ref.Name(), true)
-	if err != nil {
-		return nil, err
-	}
-
-	commit, err := repo.CommitObject(res.Hash())
+	commit, err := Commit(repo, ref)
 	if err != nil {
 		return nil, err
 	}
@@ -184,21 +275,11 @@ func Find(repo *git.Repository, ref *plumbing.Reference, path string) (object.Ob
 	}
 }
 
-// Readme returns the readme blob object if one exists.
-func Readme(repo *git.Repository, ref *plumbing.Reference) (*object.Blob, error) {
-	commit, err := repo.CommitObject(ref.Hash())
-	if err != nil {
-		return nil, err
-	}
-
-	tree, err := commit.Tree()
-	if err != nil {
-		return nil, err
-	}
-
+// Readme returns the readme file from the given tree if one exists.
+func Readme(tree *object.Tree) (*object.File, error) {
 	for _, e := range tree.Entries {
 		if e.Mode.IsFile() && readme.MatchString(e.Name) {
-			return repo.BlobObject(e.Hash)
+			return tree.TreeEntryFile(&e)
 		}
 	}
 
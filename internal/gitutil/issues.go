@@ -0,0 +1,25 @@
+package gitutil
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// closingPattern matches closing keywords followed by an issue reference.
+var closingPattern = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?)\s+#([0-9]+)\b`)
+
+// ClosingIssues returns the numbers of issues the commit message closes
+// using keywords such as "fixes #12" or "closes #3".
+func ClosingIssues(message string) []uint {
+	var numbers []uint
+	for _, match := range closingPattern.FindAllStringSubmatch(message, -1) {
+		number, err := strconv.ParseUint(match[1], 10, 32)
+		if err != nil {
+			continue
+		}
+
+		numbers = append(numbers, uint(number))
+	}
+
+	return numbers
+}
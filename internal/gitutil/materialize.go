@@ -0,0 +1,152 @@
+package gitutil
+
+import (
+	"context"
+	"io"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	chunker "github.com/ipfs/go-ipfs-chunker"
+	ipld "github.com/ipfs/go-ipld-format"
+	merkledag "github.com/ipfs/go-merkledag"
+	ufs "github.com/ipfs/go-unixfs"
+	"github.com/ipfs/go-unixfs/importer"
+	ufsio "github.com/ipfs/go-unixfs/io"
+)
+
+// Materializer converts git trees into regular unixfs directories.
+//
+// Trees and blobs are cached by hash so that content shared
+// between refs is only imported once.
+type Materializer struct {
+	ctx   context.Context
+	ds    ipld.DAGService
+	repo  *git.Repository
+	cache map[plumbing.Hash]ipld.Node
+}
+
+// NewMaterializer returns a new materializer for the given repo.
+func NewMaterializer(ctx context.Context, ds ipld.DAGService, repo *git.Repository) *Materializer {
+	return &Materializer{
+		ctx:   ctx,
+		ds:    ds,
+		repo:  repo,
+		cache: make(map[plumbing.Hash]ipld.Node),
+	}
+}
+
+// Commit returns a unixfs directory containing the tree of the given commit.
+func (m *Materializer) Commit(commit *object.Commit) (ipld.Node, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	return m.tree(tree)
+}
+
+func (m *Materializer) tree(tree *object.Tree) (ipld.Node, error) {
+	if node, ok := m.cache[tree.Hash]; ok {
+		return node, nil
+	}
+
+	dir := ufsio.NewDirectory(m.ds)
+	for _, e := range tree.Entries {
+		var node ipld.Node
+		var err error
+
+		switch {
+		case e.Mode == filemode.Dir:
+			node, err = m.subtree(e.Hash)
+		case e.Mode == filemode.Symlink:
+			node, err = m.symlink(e.Hash)
+		case e.Mode.IsFile():
+			node, err = m.blob(e.Hash)
+		default:
+			// submodules point to commits in other repositories
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := dir.AddChild(m.ctx, e.Name, node); err != nil {
+			return nil, err
+		}
+	}
+
+	node, err := dir.GetNode()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.ds.Add(m.ctx, node); err != nil {
+		return nil, err
+	}
+
+	m.cache[tree.Hash] = node
+	return node, nil
+}
+
+func (m *Materializer) subtree(hash plumbing.Hash) (ipld.Node, error) {
+	tree, err := m.repo.TreeObject(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.tree(tree)
+}
+
+func (m *Materializer) blob(hash plumbing.Hash) (ipld.Node, error) {
+	if node, ok := m.cache[hash]; ok {
+		return node, nil
+	}
+
+	blob, err := m.repo.BlobObject(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := blob.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	node, err := importer.BuildDagFromReader(m.ds, chunker.DefaultSplitter(r))
+	if err != nil {
+		return nil, err
+	}
+
+	m.cache[hash] = node
+	return node, nil
+}
+
+func (m *Materializer) symlink(hash plumbing.Hash) (ipld.Node, error) {
+	blob, err := m.repo.BlobObject(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := blob.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	target, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ufs.SymlinkData(string(target))
+	if err != nil {
+		return nil, err
+	}
+
+	node := merkledag.NodeWithData(data)
+	return node, m.ds.Add(m.ctx, node)
+}
@@ -0,0 +1,369 @@
+package gitutil
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/revlist"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+const (
+	// MergeFastForward moves the base branch to the head commit.
+	MergeFastForward = "fast-forward"
+	// MergeCommit creates a merge commit with both branches as parents.
+	MergeCommit = "merge"
+	// MergeSquash creates a single commit with the combined changes.
+	MergeSquash = "squash"
+)
+
+// MergeMethods contains all merge methods.
+var MergeMethods = []string{MergeFastForward, MergeCommit, MergeSquash}
+
+var (
+	// ErrUnrelated is returned when branches have no common history.
+	ErrUnrelated = errors.New("branches have no common history")
+	// ErrNotFastForward is returned when the base branch has diverged.
+	ErrNotFastForward = errors.New("base branch has diverged and cannot be fast-forwarded")
+	// ErrConflict is returned when both branches change the same file.
+	ErrConflict = errors.New("branches have conflicting changes")
+)
+
+// Ancestors returns the hashes of the commit and all of its ancestors.
+func Ancestors(repo *git.Repository, hash plumbing.Hash) (map[plumbing.Hash]bool, error) {
+	iter, err := repo.Log(&git.LogOptions{From: hash})
+	if err != nil {
+		return nil, err
+	}
+
+	ancestors := make(map[plumbing.Hash]bool)
+	err = iter.ForEach(func(commit *object.Commit) error {
+		ancestors[commit.Hash] = true
+		return nil
+	})
+
+	return ancestors, err
+}
+
+// MergeBase returns the best common ancestor of the base commit
+// in the base repo and the head commit in the head repo.
+//
+// The best common ancestor is not an ancestor of any other common
+// ancestor. History is walked by parents rather than commit times so
+// skewed clocks do not change the result. When there are several best
+// ancestors, as after criss-cross merges, the most recent one is used.
+//
+// The repos can be the same or share history through a fork.
+func MergeBase(base *git.Repository, baseHash plumbing.Hash, head *git.Repository, headHash plumbing.Hash) (plumbing.Hash, error) {
+	ancestors, err := Ancestors(base, baseHash)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	// walk the head history without passing shared commits
+	var candidates []*object.Commit
+	seen := make(map[plumbing.Hash]bool)
+	queue := []plumbing.Hash{headHash}
+
+	for len(queue) > 0 {
+		hash := queue[0]
+		queue = queue[1:]
+
+		if seen[hash] {
+			continue
+		}
+
+		seen[hash] = true
+		commit, err := head.CommitObject(hash)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+
+		if ancestors[hash] {
+			candidates = append(candidates, commit)
+			continue
+		}
+
+		queue = append(queue, commit.ParentHashes...)
+	}
+
+	var best *object.Commit
+	for _, commit := range candidates {
+		redundant, err := isAncestorOfAny(base, commit, candidates)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+
+		if !redundant && (best == nil || commit.Committer.When.After(best.Committer.When)) {
+			best = commit
+		}
+	}
+
+	if best == nil {
+		return plumbing.ZeroHash, ErrUnrelated
+	}
+
+	return best.Hash, nil
+}
+
+// isAncestorOfAny returns true if the commit is an ancestor of any other commit.
+func isAncestorOfAny(repo *git.Repository, commit *object.Commit, others []*object.Commit) (bool, error) {
+	for _, other := range others {
+		if other.Hash == commit.Hash {
+			continue
+		}
+
+		ancestors, err := Ancestors(repo, other.Hash)
+		if err != nil {
+			return false, err
+		}
+
+		if ancestors[commit.Hash] {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Compare returns the commits reachable from the head commit
+// that are not reachable from the merge base, newest first.
+//
+// All commits reachable from the head are returned for the zero hash.
+func Compare(repo *git.Repository, mergeBase, head plumbing.Hash) ([]*object.Commit, error) {
+	ancestors := make(map[plumbing.Hash]bool)
+	if !mergeBase.IsZero() {
+		var err error
+		if ancestors, err = Ancestors(repo, mergeBase); err != nil {
+			return nil, err
+		}
+	}
+
+	opts := git.LogOptions{
+		From:  head,
+		Order: git.LogOrderCommitterTime,
+	}
+
+	iter, err := repo.Log(&opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []*object.Commit
+	err = iter.ForEach(func(commit *object.Commit) error {
+		if !ancestors[commit.Hash] {
+			commits = append(commits, commit)
+		}
+
+		return nil
+	})
+
+	return commits, err
+}
+
+// CopyObjects copies the objects reachable from the head commit in the
+// source repo to the destination, skipping those reachable from the
+// ignored commits.
+func CopyObjects(dst storer.EncodedObjectStorer, src *git.Repository, head plumbing.Hash, ignore []plumbing.Hash) error {
+	hashes, err := revlist.Objects(src.Storer, []plumbing.Hash{head}, ignore)
+	if err != nil {
+		return err
+	}
+
+	for _, hash := range hashes {
+		if dst.HasEncodedObject(hash) == nil {
+			continue
+		}
+
+		obj, err := src.Storer.EncodedObject(plumbing.AnyObject, hash)
+		if err != nil {
+			return err
+		}
+
+		if _, err := dst.SetEncodedObject(obj); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Merge combines the head commit into the base commit using the method
+// and returns the hash of the resulting commit.
+//
+// All objects of both commits must be in the repo. The base branch
+// itself is not updated.
+func Merge(repo *git.Repository, method string, base, head *object.Commit, sig object.Signature, message string) (plumbing.Hash, error) {
+	mergeBase, err := MergeBase(repo, base.Hash, repo, head.Hash)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	if method == MergeFastForward {
+		if mergeBase != base.Hash {
+			return plumbing.ZeroHash, ErrNotFastForward
+		}
+
+		return head.Hash, nil
+	}
+
+	ancestor, err := repo.CommitObject(mergeBase)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	tree, err := mergeCommitTrees(repo.Storer, ancestor, base, head)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	commit := object.Commit{
+		Author:       sig,
+		Committer:    sig,
+		Message:      message,
+		TreeHash:     tree,
+		ParentHashes: []plumbing.Hash{base.Hash},
+	}
+
+	switch method {
+	case MergeCommit:
+		commit.ParentHashes = append(commit.ParentHashes, head.Hash)
+	case MergeSquash:
+	default:
+		return plumbing.ZeroHash, errors.New("invalid merge method")
+	}
+
+	obj := repo.Storer.NewEncodedObject()
+	if err := commit.Encode(obj); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	return repo.Storer.SetEncodedObject(obj)
+}
+
+func mergeCommitTrees(s storer.EncodedObjectStorer, ancestor, ours, theirs *object.Commit) (plumbing.Hash, error) {
+	if ours.TreeHash == theirs.TreeHash || ancestor.TreeHash == theirs.TreeHash {
+		return ours.TreeHash, nil
+	}
+
+	if ancestor.TreeHash == ours.TreeHash {
+		return theirs.TreeHash, nil
+	}
+
+	return mergeTrees(s, ancestor.TreeHash, ours.TreeHash, theirs.TreeHash)
+}
+
+// mergeTrees performs a three way merge of the trees with the given hashes.
+//
+// Entries changed on one side take that side. Entries changed on both
+// sides are only merged if they are directories on all sides that
+// exist, otherwise the merge fails with ErrConflict.
+func mergeTrees(s storer.EncodedObjectStorer, ancestor, ours, theirs plumbing.Hash) (plumbing.Hash, error) {
+	a, err := treeEntries(s, ancestor)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	o, err := treeEntries(s, ours)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	t, err := treeEntries(s, theirs)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	names := make(map[string]bool)
+	for _, entries := range []map[string]object.TreeEntry{a, o, t} {
+		for name := range entries {
+			names[name] = true
+		}
+	}
+
+	var tree object.Tree
+	for name := range names {
+		ae, aok := a[name]
+		oe, ook := o[name]
+		te, tok := t[name]
+
+		var entry object.TreeEntry
+		var keep bool
+
+		switch {
+		case sameEntry(oe, ook, te, tok):
+			entry, keep = oe, ook
+		case sameEntry(ae, aok, oe, ook):
+			entry, keep = te, tok
+		case sameEntry(ae, aok, te, tok):
+			entry, keep = oe, ook
+		case ook && tok && oe.Mode == filemode.Dir && te.Mode == filemode.Dir && (!aok || ae.Mode == filemode.Dir):
+			hash, err := mergeTrees(s, ae.Hash, oe.Hash, te.Hash)
+			if err != nil {
+				return plumbing.ZeroHash, err
+			}
+
+			entry = object.TreeEntry{Name: name, Mode: filemode.Dir, Hash: hash}
+			keep = true
+		default:
+			return plumbing.ZeroHash, ErrConflict
+		}
+
+		if keep {
+			tree.Entries = append(tree.Entries, entry)
+		}
+	}
+
+	sort.Slice(tree.Entries, func(i, j int) bool {
+		return sortName(tree.Entries[i]) < sortName(tree.Entries[j])
+	})
+
+	obj := s.NewEncodedObject()
+	if err := tree.Encode(obj); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	return s.SetEncodedObject(obj)
+}
+
+// treeEntries returns the entries of the tree by name.
+//
+// The zero hash is treated as an empty tree.
+func treeEntries(s storer.EncodedObjectStorer, hash plumbing.Hash) (map[string]object.TreeEntry, error) {
+	entries := make(map[string]object.TreeEntry)
+	if hash.IsZero() {
+		return entries, nil
+	}
+
+	tree, err := object.GetTree(s, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range tree.Entries {
+		entries[e.Name] = e
+	}
+
+	return entries, nil
+}
+
+func sameEntry(a object.TreeEntry, aok bool, b object.TreeEntry, bok bool) bool {
+	if !aok || !bok {
+		return aok == bok
+	}
+
+	return a.Hash == b.Hash && a.Mode == b.Mode
+}
+
+// sortName returns the name git uses to order tree entries.
+func sortName(e object.TreeEntry) string {
+	if e.Mode == filemode.Dir {
+		return e.Name + "/"
+	}
+
+	return e.Name
+}
@@ -0,0 +1,229 @@
+package gitutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type MergeSuite struct {
+	repo *git.Repository
+	when time.Time
+}
+
+var _ = Suite(&MergeSuite{})
+
+func (s *MergeSuite) SetUpTest(c *C) {
+	repo, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		c.Fatalf("failed to init repo: %v", err)
+	}
+
+	s.repo = repo
+	s.when = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// commit writes a commit with the files to the repo and returns its hash.
+func (s *MergeSuite) commit(c *C, repo *git.Repository, files map[string]string, when time.Time, parents ...plumbing.Hash) plumbing.Hash {
+	data := make(map[string][]byte)
+	for name, content := range files {
+		data[name] = []byte(content)
+	}
+
+	tree, err := writeTree(repo.Storer, data)
+	if err != nil {
+		c.Fatalf("failed to write tree: %v", err)
+	}
+
+	sig := object.Signature{Name: "alice", Email: "alice@example.com", When: when}
+	commit := object.Commit{
+		Author:       sig,
+		Committer:    sig,
+		Message:      "commit",
+		TreeHash:     tree,
+		ParentHashes: parents,
+	}
+
+	obj := repo.Storer.NewEncodedObject()
+	if err := commit.Encode(obj); err != nil {
+		c.Fatalf("failed to encode commit: %v", err)
+	}
+
+	hash, err := repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		c.Fatalf("failed to store commit: %v", err)
+	}
+
+	return hash
+}
+
+// files returns the contents of all files in the commit tree.
+func (s *MergeSuite) files(c *C, repo *git.Repository, hash plumbing.Hash) map[string]string {
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		c.Fatalf("failed to get commit: %v", err)
+	}
+
+	iter, err := commit.Files()
+	if err != nil {
+		c.Fatalf("failed to get files: %v", err)
+	}
+
+	files := make(map[string]string)
+	err = iter.ForEach(func(f *object.File) error {
+		content, err := f.Contents()
+		files[f.Name] = content
+		return err
+	})
+
+	if err != nil {
+		c.Fatalf("failed to read files: %v", err)
+	}
+
+	return files
+}
+
+func (s *MergeSuite) merge(c *C, method string, base, head plumbing.Hash) (plumbing.Hash, error) {
+	baseCommit, err := s.repo.CommitObject(base)
+	if err != nil {
+		c.Fatalf("failed to get base commit: %v", err)
+	}
+
+	headCommit, err := s.repo.CommitObject(head)
+	if err != nil {
+		c.Fatalf("failed to get head commit: %v", err)
+	}
+
+	sig := object.Signature{Name: "bob", Email: "bob@example.com", When: s.when}
+	return Merge(s.repo, method, baseCommit, headCommit, sig, "merge")
+}
+
+func (s *MergeSuite) TestFastForward(c *C) {
+	root := s.commit(c, s.repo, map[string]string{"README": "hello"}, s.when)
+	head := s.commit(c, s.repo, map[string]string{"README": "hello world"}, s.when.Add(time.Hour), root)
+
+	mergeBase, err := MergeBase(s.repo, root, s.repo, head)
+	c.Assert(err, IsNil)
+	c.Assert(mergeBase, Equals, root)
+
+	hash, err := s.merge(c, MergeFastForward, root, head)
+	c.Assert(err, IsNil)
+	c.Assert(hash, Equals, head)
+}
+
+func (s *MergeSuite) TestDiverged(c *C) {
+	root := s.commit(c, s.repo, map[string]string{"README": "hello", "LICENSE": "mit"}, s.when)
+	base := s.commit(c, s.repo, map[string]string{"README": "hello world", "LICENSE": "mit"}, s.when.Add(time.Hour), root)
+	head := s.commit(c, s.repo, map[string]string{"README": "hello", "LICENSE": "apache"}, s.when.Add(2*time.Hour), root)
+
+	_, err := s.merge(c, MergeFastForward, base, head)
+	c.Assert(err, Equals, ErrNotFastForward)
+
+	tests := []struct {
+		method  string
+		parents []plumbing.Hash
+	}{
+		{MergeCommit, []plumbing.Hash{base, head}},
+		{MergeSquash, []plumbing.Hash{base}},
+	}
+
+	for _, test := range tests {
+		hash, err := s.merge(c, test.method, base, head)
+		c.Assert(err, IsNil)
+
+		commit, err := s.repo.CommitObject(hash)
+		c.Assert(err, IsNil)
+		c.Assert(commit.ParentHashes, DeepEquals, test.parents)
+
+		files := s.files(c, s.repo, hash)
+		c.Assert(files, DeepEquals, map[string]string{"README": "hello world", "LICENSE": "apache"})
+	}
+
+	commits, err := Compare(s.repo, root, head)
+	c.Assert(err, IsNil)
+	c.Assert(commits, HasLen, 1)
+	c.Assert(commits[0].Hash, Equals, head)
+}
+
+func (s *MergeSuite) TestConflict(c *C) {
+	root := s.commit(c, s.repo, map[string]string{"README": "hello"}, s.when)
+	base := s.commit(c, s.repo, map[string]string{"README": "hello world"}, s.when.Add(time.Hour), root)
+	head := s.commit(c, s.repo, map[string]string{"README": "goodbye"}, s.when.Add(2*time.Hour), root)
+
+	for _, method := range []string{MergeCommit, MergeSquash} {
+		_, err := s.merge(c, method, base, head)
+		c.Assert(err, Equals, ErrConflict)
+	}
+}
+
+func (s *MergeSuite) TestDirectoryMerge(c *C) {
+	root := s.commit(c, s.repo, map[string]string{"docs/a.md": "a"}, s.when)
+	base := s.commit(c, s.repo, map[string]string{"docs/a.md": "a", "docs/b.md": "b"}, s.when.Add(time.Hour), root)
+	head := s.commit(c, s.repo, map[string]string{"docs/a.md": "a", "docs/c.md": "c"}, s.when.Add(2*time.Hour), root)
+
+	hash, err := s.merge(c, MergeCommit, base, head)
+	c.Assert(err, IsNil)
+
+	files := s.files(c, s.repo, hash)
+	c.Assert(files, DeepEquals, map[string]string{"docs/a.md": "a", "docs/b.md": "b", "docs/c.md": "c"})
+}
+
+func (s *MergeSuite) TestUnrelated(c *C) {
+	base := s.commit(c, s.repo, map[string]string{"README": "base"}, s.when)
+	head := s.commit(c, s.repo, map[string]string{"README": "head"}, s.when)
+
+	_, err := MergeBase(s.repo, base, s.repo, head)
+	c.Assert(err, Equals, ErrUnrelated)
+}
+
+func (s *MergeSuite) TestMergeBaseClockSkew(c *C) {
+	// the root commit has a clock far ahead of its descendants
+	root := s.commit(c, s.repo, map[string]string{"README": "root"}, s.when.Add(24*time.Hour))
+	base := s.commit(c, s.repo, map[string]string{"README": "base"}, s.when, root)
+	side := s.commit(c, s.repo, map[string]string{"README": "root", "side": "side"}, s.when.Add(48*time.Hour), root)
+	head := s.commit(c, s.repo, map[string]string{"README": "base", "side": "side"}, s.when.Add(49*time.Hour), side, base)
+
+	mergeBase, err := MergeBase(s.repo, base, s.repo, head)
+	c.Assert(err, IsNil)
+	c.Assert(mergeBase, Equals, base)
+
+	hash, err := s.merge(c, MergeFastForward, base, head)
+	c.Assert(err, IsNil)
+	c.Assert(hash, Equals, head)
+}
+
+func (s *MergeSuite) TestCopyObjectsFromFork(c *C) {
+	root := s.commit(c, s.repo, map[string]string{"README": "hello"}, s.when)
+
+	fork, err := git.Init(memory.NewStorage(), nil)
+	c.Assert(err, IsNil)
+
+	err = CopyObjects(fork.Storer, s.repo, root, nil)
+	c.Assert(err, IsNil)
+
+	head := s.commit(c, fork, map[string]string{"README": "hello", "src/main.go": "package main"}, s.when.Add(time.Hour), root)
+	c.Assert(s.repo.Storer.HasEncodedObject(head), Equals, plumbing.ErrObjectNotFound)
+
+	mergeBase, err := MergeBase(s.repo, root, fork, head)
+	c.Assert(err, IsNil)
+	c.Assert(mergeBase, Equals, root)
+
+	err = CopyObjects(s.repo.Storer, fork, head, []plumbing.Hash{mergeBase})
+	c.Assert(err, IsNil)
+
+	hash, err := s.merge(c, MergeFastForward, root, head)
+	c.Assert(err, IsNil)
+	c.Assert(hash, Equals, head)
+
+	files := s.files(c, s.repo, hash)
+	c.Assert(files, DeepEquals, map[string]string{"README": "hello", "src/main.go": "package main"})
+}
@@ -0,0 +1,198 @@
+package api
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
+)
+
+const (
+	// APIPerPage is the default amount of items per page.
+	APIPerPage = 30
+	// APIMaxPerPage is the maximum amount of items per page.
+	APIMaxPerPage = 100
+)
+
+var (
+	errUnauthorized   = errors.New("authentication required")
+	errBadCredentials = errors.New("invalid credentials")
+	errMediaType      = errors.New("content type must be application/json")
+	errNotFound       = errors.New("not found")
+	errMethod         = errors.New("method not allowed")
+	errOwner          = errors.New("repositories can only be created for yourself or organizations you own")
+)
+
+//go:embed openapi.yaml
+var openapi []byte
+
+type API core.Server
+
+// OpenAPI writes the OpenAPI description of the API.
+func (s *API) OpenAPI(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(openapi)
+}
+
+// NotFound writes a not found error.
+func (s *API) NotFound(w http.ResponseWriter, req *http.Request) {
+	writeError(w, http.StatusNotFound, errNotFound)
+}
+
+// MethodNotAllowed writes a method not allowed error.
+func (s *API) MethodNotAllowed(w http.ResponseWriter, req *http.Request) {
+	writeError(w, http.StatusMethodNotAllowed, errMethod)
+}
+
+// authenticate returns the user making the request.
+//
+// Requests are authenticated by a bearer token, basic auth
+// credentials, or a session cookie. A nil user is returned
+// for anonymous requests.
+func (s *API) authenticate(req *http.Request) (*database.User, error) {
+	if header := req.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		var token database.Token
+		if err := token.FindBySecret(s.DB, strings.TrimPrefix(header, "Bearer ")); err != nil {
+			return nil, errBadCredentials
+		}
+
+		return &token.User, nil
+	}
+
+	if username, password, ok := req.BasicAuth(); ok {
+		var user database.User
+		if err := user.FindByUsername(s.DB, username); err != nil {
+			return nil, errBadCredentials
+		}
+
+		if err := user.CheckPassword(password); err != nil {
+			return nil, errBadCredentials
+		}
+
+		return &user, nil
+	}
+
+	sess, err := session.Get(req, s.DB)
+	if err != nil {
+		return nil, nil
+	}
+
+	return &sess.User, nil
+}
+
+// requireUser returns the user making the request or writes
+// an error response if the request is not authenticated.
+func (s *API) requireUser(w http.ResponseWriter, req *http.Request) (*database.User, bool) {
+	user, err := s.authenticate(req)
+	if err == nil && user == nil {
+		err = errUnauthorized
+	}
+
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="multiverse"`)
+		writeError(w, http.StatusUnauthorized, err)
+		return nil, false
+	}
+
+	return user, true
+}
+
+// findRepo returns the repo named in the request path if the
+// user making the request can read it.
+func (s *API) findRepo(req *http.Request) (*database.Repo, error) {
+	params := mux.Vars(req)
+
+	viewer, err := s.authenticate(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var user database.User
+	if err := user.FindByUsername(s.DB, params["user"]); err != nil {
+		return nil, err
+	}
+
+	var repo database.Repo
+	if err := repo.FindByNameAndUserID(s.DB, params["repo"], user.ID); err != nil {
+		return nil, err
+	}
+
+	// hidden repos are reported as missing
+	if !repo.CanRead(s.DB, viewer) {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	repo.User = user
+	return &repo, nil
+}
+
+// decode reads the JSON request body into v.
+func decode(req *http.Request, v interface{}) error {
+	mediatype, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || mediatype != "application/json" {
+		return errMediaType
+	}
+
+	return json.NewDecoder(req.Body).Decode(v)
+}
+
+// paginate returns the offset and limit query parameters.
+func paginate(req *http.Request) (int, int, error) {
+	offset, limit := 0, APIPerPage
+
+	var err error
+	if value := req.URL.Query().Get("offset"); value != "" {
+		if offset, err = strconv.Atoi(value); err != nil || offset < 0 {
+			return 0, 0, errors.New("offset must be a positive integer")
+		}
+	}
+
+	if value := req.URL.Query().Get("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 || limit > APIMaxPerPage {
+			return 0, 0, errors.New("limit must be between 1 and 100")
+		}
+	}
+
+	return offset, limit, nil
+}
+
+// writeJSON writes the value as a JSON response.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes the error as a JSON response.
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, Error{Error: err.Error()})
+}
+
+// errorStatus returns the response status for the error.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, errBadCredentials):
+		return http.StatusUnauthorized
+	case errors.Is(err, gorm.ErrRecordNotFound),
+		errors.Is(err, plumbing.ErrReferenceNotFound),
+		errors.Is(err, plumbing.ErrObjectNotFound),
+		errors.Is(err, object.ErrEntryNotFound),
+		errors.Is(err, object.ErrDirectoryNotFound),
+		errors.Is(err, object.ErrFileNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,240 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm/logger"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
+)
+
+// testAPI contains an API backed by an in-memory database
+// with a user, an access token, sessions and repos of that user.
+type testAPI struct {
+	api     *API
+	router  *mux.Router
+	user    database.User
+	token   database.Token
+	sess    database.Session
+	expired database.Session
+}
+
+func newTestAPI(t *testing.T) *testAPI {
+	db, err := database.Open(sqlite.Open("file::memory:"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get database: %v", err)
+	}
+
+	// every connection would open a separate in-memory database
+	sqlDB.SetMaxOpenConns(1)
+	db.Logger = logger.Discard
+
+	ta := testAPI{api: &API{DB: db}}
+
+	ta.user = database.User{Username: "alice", Email: "alice@example.com", Password: "password"}
+	if err := ta.user.Create(db); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	ta.token = database.Token{UserID: ta.user.ID, Name: "test"}
+	if err := ta.token.Create(db); err != nil {
+		t.Fatalf("failed to create token: %v", err)
+	}
+
+	ta.sess = database.Session{UserID: ta.user.ID, ExpiresAt: time.Now().Add(time.Hour)}
+	if err := ta.sess.Create(db); err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	ta.expired = database.Session{UserID: ta.user.ID, ExpiresAt: time.Now().Add(-time.Hour)}
+	if err := ta.expired.Create(db); err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	repo := database.Repo{Name: "hello", UserID: ta.user.ID}
+	if err := repo.Create(db); err != nil {
+		t.Fatalf("failed to create repo: %v", err)
+	}
+
+	private := database.Repo{Name: "secret", UserID: ta.user.ID, Visibility: database.RepoPrivate}
+	if err := private.Create(db); err != nil {
+		t.Fatalf("failed to create repo: %v", err)
+	}
+
+	ta.router = mux.NewRouter()
+	ta.router.NotFoundHandler = http.HandlerFunc(ta.api.NotFound)
+	ta.router.MethodNotAllowedHandler = http.HandlerFunc(ta.api.MethodNotAllowed)
+	ta.router.HandleFunc("/user", ta.api.CurrentUser).Methods(http.MethodGet)
+	ta.router.HandleFunc("/repos/{user}/{repo}", ta.api.ReadRepo).Methods(http.MethodGet)
+
+	return &ta
+}
+
+// serve returns the recorded response of the request after applying auth.
+func (ta *testAPI) serve(method, target string, auth func(*http.Request)) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	if auth != nil {
+		auth(req)
+	}
+
+	rec := httptest.NewRecorder()
+	ta.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCurrentUserAuth(t *testing.T) {
+	ta := newTestAPI(t)
+
+	tests := []struct {
+		name   string
+		auth   func(*http.Request)
+		status int
+	}{
+		{"bearer token", func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer "+ta.token.Secret)
+		}, http.StatusOK},
+		{"basic auth", func(req *http.Request) {
+			req.SetBasicAuth("alice", "password")
+		}, http.StatusOK},
+		{"session cookie", func(req *http.Request) {
+			req.AddCookie(&http.Cookie{Name: session.CookieName, Value: ta.sess.ID})
+		}, http.StatusOK},
+		{"anonymous", nil, http.StatusUnauthorized},
+		{"bad token", func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer invalid")
+		}, http.StatusUnauthorized},
+		{"bad password", func(req *http.Request) {
+			req.SetBasicAuth("alice", "wrong")
+		}, http.StatusUnauthorized},
+		{"unknown user", func(req *http.Request) {
+			req.SetBasicAuth("bob", "password")
+		}, http.StatusUnauthorized},
+		{"unknown session", func(req *http.Request) {
+			req.AddCookie(&http.Cookie{Name: session.CookieName, Value: "unknown"})
+		}, http.StatusUnauthorized},
+		{"expired session", func(req *http.Request) {
+			req.AddCookie(&http.Cookie{Name: session.CookieName, Value: ta.expired.ID})
+		}, http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		rec := ta.serve(http.MethodGet, "/user", test.auth)
+		if rec.Code != test.status {
+			t.Errorf("%s: status %d, want %d", test.name, rec.Code, test.status)
+			continue
+		}
+
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("%s: content type %q", test.name, ct)
+		}
+
+		if test.status != http.StatusOK {
+			if rec.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("%s: missing WWW-Authenticate header", test.name)
+			}
+
+			continue
+		}
+
+		var user User
+		if err := json.NewDecoder(rec.Body).Decode(&user); err != nil {
+			t.Errorf("%s: failed to decode user: %v", test.name, err)
+		}
+
+		if user.Username != "alice" {
+			t.Errorf("%s: username %q", test.name, user.Username)
+		}
+	}
+}
+
+func TestErrorBodies(t *testing.T) {
+	ta := newTestAPI(t)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		auth   func(*http.Request)
+		status int
+		error  string
+	}{
+		{"unauthorized", http.MethodGet, "/user", nil, http.StatusUnauthorized, errUnauthorized.Error()},
+		{"bad credentials", http.MethodGet, "/user", func(req *http.Request) {
+			req.SetBasicAuth("alice", "wrong")
+		}, http.StatusUnauthorized, errBadCredentials.Error()},
+		{"hidden repo", http.MethodGet, "/repos/alice/secret", nil, http.StatusNotFound, "record not found"},
+		{"missing repo", http.MethodGet, "/repos/alice/missing", nil, http.StatusNotFound, "record not found"},
+		{"missing route", http.MethodGet, "/missing", nil, http.StatusNotFound, errNotFound.Error()},
+		{"wrong method", http.MethodDelete, "/user", nil, http.StatusMethodNotAllowed, errMethod.Error()},
+	}
+
+	for _, test := range tests {
+		rec := ta.serve(test.method, test.target, test.auth)
+		if rec.Code != test.status {
+			t.Errorf("%s: status %d, want %d", test.name, rec.Code, test.status)
+			continue
+		}
+
+		var body Error
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Errorf("%s: failed to decode error: %v", test.name, err)
+			continue
+		}
+
+		if body.Error != test.error {
+			t.Errorf("%s: error %q, want %q", test.name, body.Error, test.error)
+		}
+	}
+}
+
+func TestReadRepo(t *testing.T) {
+	ta := newTestAPI(t)
+
+	rec := ta.serve(http.MethodGet, "/repos/alice/hello", nil)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var repo Repo
+	if err := json.NewDecoder(rec.Body).Decode(&repo); err != nil {
+		t.Fatalf("failed to decode repo: %v", err)
+	}
+
+	if repo.Name != "hello" {
+		t.Fatalf("name %q", repo.Name)
+	}
+}
+
+func TestReadPrivateRepo(t *testing.T) {
+	ta := newTestAPI(t)
+
+	rec := ta.serve(http.MethodGet, "/repos/alice/secret", func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer "+ta.token.Secret)
+	})
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var repo Repo
+	if err := json.NewDecoder(rec.Body).Decode(&repo); err != nil {
+		t.Fatalf("failed to decode repo: %v", err)
+	}
+
+	if repo.Name != "secret" {
+		t.Fatalf("name %q", repo.Name)
+	}
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/gorilla/mux"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/gitutil"
+)
+
+// Commits writes a page of the commit log starting at the ref query parameter.
+func (s *API) Commits(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	refname := req.URL.Query().Get("ref")
+
+	offset, limit, err := paginate(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	repo, err := s.findRepo(req)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	git, err := (*core.Server)(s).OpenRepo(ctx, repo)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	head, err := gitutil.HeadOrDefault(git)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	if head == nil {
+		writeJSON(w, http.StatusOK, []Commit{})
+		return
+	}
+
+	if refname != "" {
+		head, err = gitutil.Resolve(git, refname)
+	}
+
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	logs, err := gitutil.Logs(git, head, offset, limit)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	res := make([]Commit, len(logs))
+	for i, c := range logs {
+		res[i] = newCommit(c)
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+// ReadCommit writes the commit with the given hash.
+func (s *API) ReadCommit(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	params := mux.Vars(req)
+
+	repo, err := s.findRepo(req)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	git, err := (*core.Server)(s).OpenRepo(ctx, repo)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	commit, err := git.CommitObject(plumbing.NewHash(params["hash"]))
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newCommit(commit))
+}
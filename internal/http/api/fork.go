@@ -0,0 +1,87 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/webhook"
+)
+
+// Forks writes the forks of the repo visible to the user.
+func (s *API) Forks(w http.ResponseWriter, req *http.Request) {
+	viewer, err := s.authenticate(req)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	repo, err := s.findRepo(req)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	forks, err := database.FindForksByParentID(s.DB.Scopes(database.VisibleRepos(viewer)), repo.ID)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	res := make([]Repo, len(forks))
+	for i := range forks {
+		forks[i].Parent = repo
+		res[i] = newRepo(&forks[i])
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+// CreateFork forks the repo for the authenticated user.
+func (s *API) CreateFork(w http.ResponseWriter, req *http.Request) {
+	user, ok := s.requireUser(w, req)
+	if !ok {
+		return
+	}
+
+	repo, err := s.findRepo(req)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	var other database.Repo
+	if err := other.FindByNameAndUserID(s.DB, repo.Name, user.ID); err == nil {
+		writeError(w, http.StatusConflict, errors.New("repository already exists"))
+		return
+	}
+
+	fork, err := (*core.Server)(s).ForkRepo(repo, user.ID)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	if err := (*core.Server)(s).RecordActivity(user.ID, fork, database.ActivityFork); err != nil {
+		log.Println(err)
+	}
+
+	payload := webhook.Payload{
+		Event: database.WebhookCreateEvent,
+		Repository: webhook.Repository{
+			Owner: user.Username,
+			Name:  fork.Name,
+			CID:   fork.CID,
+		},
+	}
+
+	if err := webhook.Trigger(s.DB, s.Key, fork, &payload); err != nil {
+		log.Println(err)
+	}
+
+	fork.User = *user
+	fork.Parent = repo
+	writeJSON(w, http.StatusCreated, newRepo(fork))
+}
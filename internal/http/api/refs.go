@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/gitutil"
+)
+
+// Branches writes the branches of the repo.
+func (s *API) Branches(w http.ResponseWriter, req *http.Request) {
+	s.refs(w, req, gitutil.Branches)
+}
+
+// Tags writes the tags of the repo.
+func (s *API) Tags(w http.ResponseWriter, req *http.Request) {
+	s.refs(w, req, gitutil.Tags)
+}
+
+// refs writes the references of the repo returned by list.
+func (s *API) refs(w http.ResponseWriter, req *http.Request, list func(*git.Repository) ([]*plumbing.Reference, error)) {
+	ctx := req.Context()
+
+	repo, err := s.findRepo(req)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	git, err := (*core.Server)(s).OpenRepo(ctx, repo)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	refs, err := list(git)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	res := make([]Ref, len(refs))
+	for i, r := range refs {
+		res[i] = newRef(r)
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
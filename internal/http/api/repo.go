@@ -0,0 +1,136 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/webhook"
+)
+
+// RepoParams contains the parameters for creating a repo.
+type RepoParams struct {
+	Owner       string `json:"owner"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Pages       bool   `json:"pages"`
+	Visibility  string `json:"visibility"`
+	Encrypted   bool   `json:"encrypted"`
+}
+
+// ListRepos writes the most recently updated repos.
+func (s *API) ListRepos(w http.ResponseWriter, req *http.Request) {
+	offset, limit, err := paginate(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	viewer, err := s.authenticate(req)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	repos, err := database.SearchRepos(s.DB.Scopes(database.VisibleRepos(viewer)), req.URL.Query().Get("q"), req.URL.Query().Get("sort"), offset, limit)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	res := make([]Repo, len(repos))
+	for i := range repos {
+		res[i] = newRepo(&repos[i])
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+// CreateRepo creates a repo owned by the authenticated user
+// or an organization they own.
+func (s *API) CreateRepo(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	user, ok := s.requireUser(w, req)
+	if !ok {
+		return
+	}
+
+	var params RepoParams
+	if err := decode(req, &params); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	owner := user
+	if params.Owner != "" && params.Owner != user.Username {
+		var org database.User
+		if err := org.FindByUsername(s.DB, params.Owner); err != nil {
+			writeError(w, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		if !org.Org || !database.IsOrgOwner(s.DB, org.ID, user.ID) {
+			writeError(w, http.StatusForbidden, errOwner)
+			return
+		}
+
+		owner = &org
+	}
+
+	var repo database.Repo
+	if err := repo.FindByNameAndUserID(s.DB, params.Name, owner.ID); err == nil {
+		writeError(w, http.StatusConflict, errors.New("repository already exists"))
+		return
+	}
+
+	// acquire a pinlock so GC doesn't wipe out changes
+	defer s.Node.Blockstore.PinLock().Unlock()
+
+	repo = database.Repo{
+		Name:        params.Name,
+		Description: params.Description,
+		UserID:      owner.ID,
+		Pages:       params.Pages,
+		Visibility:  params.Visibility,
+		Encrypted:   params.Encrypted,
+	}
+
+	if err := (*core.Server)(s).CreateRepo(ctx, &repo); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	if err := (*core.Server)(s).RecordActivity(user.ID, &repo, database.ActivityCreate); err != nil {
+		log.Println(err)
+	}
+
+	payload := webhook.Payload{
+		Event: database.WebhookCreateEvent,
+		Repository: webhook.Repository{
+			Owner: owner.Username,
+			Name:  repo.Name,
+			CID:   repo.CID,
+		},
+	}
+
+	if err := webhook.Trigger(s.DB, s.Key, &repo, &payload); err != nil {
+		log.Println(err)
+	}
+
+	repo.User = *owner
+	writeJSON(w, http.StatusCreated, newRepo(&repo))
+}
+
+// ReadRepo writes the repo with the given owner and name.
+func (s *API) ReadRepo(w http.ResponseWriter, req *http.Request) {
+	repo, err := s.findRepo(req)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newRepo(repo))
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+)
+
+// TokenParams contains the parameters for creating a token.
+type TokenParams struct {
+	Name string `json:"name"`
+}
+
+// ListTokens writes the tokens of the authenticated user.
+func (s *API) ListTokens(w http.ResponseWriter, req *http.Request) {
+	user, ok := s.requireUser(w, req)
+	if !ok {
+		return
+	}
+
+	tokens, err := database.FindTokensByUserID(s.DB, user.ID)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	res := make([]Token, len(tokens))
+	for i := range tokens {
+		res[i] = newToken(&tokens[i])
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+// CreateToken creates a token for the authenticated user.
+func (s *API) CreateToken(w http.ResponseWriter, req *http.Request) {
+	user, ok := s.requireUser(w, req)
+	if !ok {
+		return
+	}
+
+	var params TokenParams
+	if err := decode(req, &params); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	token := database.Token{
+		UserID: user.ID,
+		Name:   params.Name,
+	}
+
+	if err := token.Create(s.DB); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, newToken(&token))
+}
+
+// DeleteToken deletes a token of the authenticated user.
+func (s *API) DeleteToken(w http.ResponseWriter, req *http.Request) {
+	user, ok := s.requireUser(w, req)
+	if !ok {
+		return
+	}
+
+	var token database.Token
+	if err := token.FindByIDAndUserID(s.DB, mux.Vars(req)["id"], user.ID); err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	if err := token.Delete(s.DB); err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/gorilla/mux"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/gitutil"
+)
+
+// MaxBlobSize is the largest blob size returned by the API.
+const MaxBlobSize = 10 * 1024 * 1024
+
+var (
+	errNotTree = errors.New("path is not a tree")
+	errNotBlob = errors.New("path is not a blob")
+)
+
+// Tree writes the tree at the ref and path.
+func (s *API) Tree(w http.ResponseWriter, req *http.Request) {
+	ref, path, obj, ok := s.find(w, req)
+	if !ok {
+		return
+	}
+
+	tree, ok := obj.(*object.Tree)
+	if !ok {
+		writeError(w, http.StatusNotFound, errNotTree)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newTree(ref, path, tree))
+}
+
+// Blob writes the blob at the ref and path with base64 encoded contents.
+func (s *API) Blob(w http.ResponseWriter, req *http.Request) {
+	ref, path, obj, ok := s.find(w, req)
+	if !ok {
+		return
+	}
+
+	blob, ok := obj.(*object.Blob)
+	if !ok {
+		writeError(w, http.StatusNotFound, errNotBlob)
+		return
+	}
+
+	if blob.Size > MaxBlobSize {
+		writeError(w, http.StatusRequestEntityTooLarge, errors.New("blob is too large"))
+		return
+	}
+
+	res, err := newBlob(ref, path, blob)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+// find returns the ref name, path, and object at the refpath of the request.
+//
+// The default branch is used when the refpath is empty.
+func (s *API) find(w http.ResponseWriter, req *http.Request) (string, string, object.Object, bool) {
+	ctx := req.Context()
+	params := mux.Vars(req)
+	refpath := params["refpath"]
+
+	repo, err := s.findRepo(req)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return "", "", nil, false
+	}
+
+	git, err := (*core.Server)(s).OpenRepo(ctx, repo)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return "", "", nil, false
+	}
+
+	if refpath == "" {
+		head, err := gitutil.HeadOrDefault(git)
+		if err != nil {
+			writeError(w, errorStatus(err), err)
+			return "", "", nil, false
+		}
+
+		if head == nil {
+			writeError(w, http.StatusNotFound, errors.New("repository is empty"))
+			return "", "", nil, false
+		}
+
+		refpath = head.Name().String()
+	}
+
+	ref, subpath, err := gitutil.RefPath(git, refpath)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return "", "", nil, false
+	}
+
+	obj, err := gitutil.Find(git, ref, subpath)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return "", "", nil, false
+	}
+
+	return ref.Name().String(), subpath, obj, true
+}
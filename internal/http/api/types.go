@@ -0,0 +1,227 @@
+package api
+
+import (
+	"encoding/base64"
+	"io"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+)
+
+// Error is the body of error responses.
+type Error struct {
+	Error string `json:"error"`
+}
+
+// User contains public user details.
+type User struct {
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name,omitempty"`
+	Bio         string    `json:"bio,omitempty"`
+	Website     string    `json:"website,omitempty"`
+	AvatarCID   string    `json:"avatar_cid,omitempty"`
+	Org         bool      `json:"org"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Repo contains repository details.
+type Repo struct {
+	Owner       string    `json:"owner"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CID         string    `json:"cid"`
+	Pages       bool      `json:"pages"`
+	Visibility  string    `json:"visibility"`
+	Encrypted   bool      `json:"encrypted"`
+	Parent      string    `json:"parent,omitempty"`
+	Stars       int       `json:"stars"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Token contains API token details.
+//
+// The secret is only returned when the token is created.
+type Token struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Ref contains a branch or tag.
+type Ref struct {
+	Name  string `json:"name"`
+	Short string `json:"short"`
+	Hash  string `json:"hash"`
+}
+
+// Signature contains a commit author or committer.
+type Signature struct {
+	Name  string    `json:"name"`
+	Email string    `json:"email"`
+	When  time.Time `json:"when"`
+}
+
+// Commit contains commit details.
+type Commit struct {
+	Hash      string    `json:"hash"`
+	Tree      string    `json:"tree"`
+	Parents   []string  `json:"parents"`
+	Author    Signature `json:"author"`
+	Committer Signature `json:"committer"`
+	Message   string    `json:"message"`
+}
+
+// TreeEntry contains a tree entry.
+type TreeEntry struct {
+	Name string `json:"name"`
+	Mode string `json:"mode"`
+	Type string `json:"type"`
+	Hash string `json:"hash"`
+}
+
+// Tree contains the entries of a tree.
+type Tree struct {
+	Ref     string      `json:"ref"`
+	Path    string      `json:"path"`
+	Hash    string      `json:"hash"`
+	Entries []TreeEntry `json:"entries"`
+}
+
+// Blob contains the base64 encoded contents of a blob.
+type Blob struct {
+	Ref      string `json:"ref"`
+	Path     string `json:"path"`
+	Hash     string `json:"hash"`
+	Size     int64  `json:"size"`
+	Encoding string `json:"encoding"`
+	Content  string `json:"content"`
+}
+
+func newUser(u *database.User) User {
+	return User{
+		Username:    u.Username,
+		DisplayName: u.DisplayName,
+		Bio:         u.Bio,
+		Website:     u.Website,
+		AvatarCID:   u.AvatarCID,
+		Org:         u.Org,
+		CreatedAt:   u.CreatedAt,
+	}
+}
+
+func newRepo(r *database.Repo) Repo {
+	var parent string
+	if r.Parent != nil {
+		parent = r.Parent.User.Username + "/" + r.Parent.Name
+	}
+
+	return Repo{
+		Owner:       r.User.Username,
+		Name:        r.Name,
+		Description: r.Description,
+		CID:         r.CID,
+		Pages:       r.Pages,
+		Visibility:  r.Visibility,
+		Encrypted:   r.Encrypted,
+		Parent:      parent,
+		Stars:       r.Stars,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+}
+
+func newToken(t *database.Token) Token {
+	return Token{
+		ID:        t.ID,
+		Name:      t.Name,
+		Secret:    t.Secret,
+		CreatedAt: t.CreatedAt,
+	}
+}
+
+func newRef(r *plumbing.Reference) Ref {
+	return Ref{
+		Name:  r.Name().String(),
+		Short: r.Name().Short(),
+		Hash:  r.Hash().String(),
+	}
+}
+
+func newSignature(s object.Signature) Signature {
+	return Signature{
+		Name:  s.Name,
+		Email: s.Email,
+		When:  s.When,
+	}
+}
+
+func newCommit(c *object.Commit) Commit {
+	parents := make([]string, len(c.ParentHashes))
+	for i, p := range c.ParentHashes {
+		parents[i] = p.String()
+	}
+
+	return Commit{
+		Hash:      c.Hash.String(),
+		Tree:      c.TreeHash.String(),
+		Parents:   parents,
+		Author:    newSignature(c.Author),
+		Committer: newSignature(c.Committer),
+		Message:   c.Message,
+	}
+}
+
+func newTree(ref, path string, t *object.Tree) Tree {
+	entries := make([]TreeEntry, len(t.Entries))
+	for i, e := range t.Entries {
+		kind := "blob"
+		switch {
+		case e.Mode == filemode.Dir:
+			kind = "tree"
+		case e.Mode == filemode.Submodule:
+			kind = "commit"
+		}
+
+		entries[i] = TreeEntry{
+			Name: e.Name,
+			Mode: e.Mode.String(),
+			Type: kind,
+			Hash: e.Hash.String(),
+		}
+	}
+
+	return Tree{
+		Ref:     ref,
+		Path:    path,
+		Hash:    t.Hash.String(),
+		Entries: entries,
+	}
+}
+
+func newBlob(ref, path string, b *object.Blob) (Blob, error) {
+	r, err := b.Reader()
+	if err != nil {
+		return Blob{}, err
+	}
+	defer r.Close()
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return Blob{}, err
+	}
+
+	return Blob{
+		Ref:      ref,
+		Path:     path,
+		Hash:     b.Hash.String(),
+		Size:     b.Size,
+		Encoding: "base64",
+		Content:  base64.StdEncoding.EncodeToString(content),
+	}, nil
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+)
+
+// CurrentUser writes the authenticated user.
+func (s *API) CurrentUser(w http.ResponseWriter, req *http.Request) {
+	user, ok := s.requireUser(w, req)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newUser(user))
+}
+
+// ReadUser writes the user with the given username.
+func (s *API) ReadUser(w http.ResponseWriter, req *http.Request) {
+	username := mux.Vars(req)["user"]
+
+	var user database.User
+	if err := user.FindByUsername(s.DB, username); err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newUser(&user))
+}
+
+// UserRepos writes the repos owned by the user with the given username.
+func (s *API) UserRepos(w http.ResponseWriter, req *http.Request) {
+	username := mux.Vars(req)["user"]
+
+	offset, limit, err := paginate(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	viewer, err := s.authenticate(req)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	var user database.User
+	if err := user.FindByUsername(s.DB, username); err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	var repos []database.Repo
+	if err := s.DB.Scopes(database.VisibleRepos(viewer)).Preload("Parent.User").Where("user_id = ?", user.ID).Order("name").Offset(offset).Limit(limit).Find(&repos).Error; err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	res := make([]Repo, len(repos))
+	for i := range repos {
+		repos[i].User = user
+		res[i] = newRepo(&repos[i])
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
@@ -33,7 +33,7 @@ func (s *Auth) LogInForm(w http.ResponseWriter, req *http.Request) {
 		UserID: user.ID,
 	}
 
-	if err := session.Set(w, s.DB, &sess); err != nil {
+	if err := session.Set(w, req, s.DB, &sess); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
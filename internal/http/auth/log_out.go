@@ -7,6 +7,10 @@ import (
 )
 
 func (s *Auth) LogOut(w http.ResponseWriter, req *http.Request) {
-	session.Clear(w)
+	if err := session.Delete(w, req, s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	http.Redirect(w, req, "/", http.StatusSeeOther)
 }
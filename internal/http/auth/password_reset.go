@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/view"
+)
+
+var (
+	errResetToken      = errors.New("password reset link is invalid or expired")
+	errPasswordConfirm = errors.New("passwords do not match")
+)
+
+// resetSent is shown whether or not an account exists so addresses cannot be probed.
+const resetSent = "If an account with that email exists, a password reset link has been sent to it."
+
+func (s *Auth) PasswordReset(w http.ResponseWriter, req *http.Request) {
+	view.Render(w, "password_reset.html", nil)
+}
+
+func (s *Auth) PasswordResetForm(w http.ResponseWriter, req *http.Request) {
+	data := make(map[string]interface{})
+	email := req.FormValue("email")
+
+	// checked before the lookup so the response does not reveal accounts
+	if s.Mailer == nil {
+		data["Error"] = core.ErrMailDisabled.Error()
+		view.Render(w, "password_reset.html", data)
+		return
+	}
+
+	var user database.User
+	if err := user.FindByEmail(s.DB, email); err == nil && !user.Org {
+		if err := (*core.Server)(s).SendPasswordReset(&user); err != nil {
+			log.Println(err)
+		}
+	}
+
+	data["Message"] = resetSent
+	view.Render(w, "password_reset.html", data)
+}
+
+func (s *Auth) NewPassword(w http.ResponseWriter, req *http.Request) {
+	data := make(map[string]interface{})
+
+	if _, err := s.findResetToken(req); err != nil {
+		data["Error"] = err.Error()
+		view.Render(w, "password_reset.html", data)
+		return
+	}
+
+	data["Token"] = true
+	view.Render(w, "password_reset.html", data)
+}
+
+func (s *Auth) NewPasswordForm(w http.ResponseWriter, req *http.Request) {
+	data := make(map[string]interface{})
+	password := req.FormValue("password")
+	confirm := req.FormValue("confirm")
+
+	token, err := s.findResetToken(req)
+	if err != nil {
+		data["Error"] = err.Error()
+		view.Render(w, "password_reset.html", data)
+		return
+	}
+
+	data["Token"] = true
+
+	if password != confirm {
+		data["Error"] = errPasswordConfirm.Error()
+		view.Render(w, "password_reset.html", data)
+		return
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		// consuming the token first makes concurrent requests with it fail
+		if err := token.Consume(tx); err != nil {
+			return errResetToken
+		}
+
+		if err := token.User.UpdatePassword(tx, password); err != nil {
+			return err
+		}
+
+		if err := database.DeleteUserTokens(tx, token.UserID, database.TokenPasswordReset); err != nil {
+			return err
+		}
+
+		// sessions started with the previous password are no longer trusted
+		return database.DeleteSessionsByUserID(tx, token.UserID)
+	})
+
+	if err != nil {
+		data["Error"] = err.Error()
+		view.Render(w, "password_reset.html", data)
+		return
+	}
+
+	http.Redirect(w, req, "/_log_in", http.StatusSeeOther)
+}
+
+// findResetToken returns the password reset token from the request URL.
+//
+// Tokens sent to a previous email address of the user are rejected.
+func (s *Auth) findResetToken(req *http.Request) (*database.UserToken, error) {
+	var token database.UserToken
+	if err := token.FindBySecretAndPurpose(s.DB, mux.Vars(req)["token"], database.TokenPasswordReset); err != nil {
+		return nil, errResetToken
+	}
+
+	if token.Email != token.User.Email {
+		return nil, errResetToken
+	}
+
+	return &token, nil
+}
@@ -1,10 +1,12 @@
 package auth
 
 import (
+	"log"
 	"net/http"
 
 	"gorm.io/gorm"
 
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
 	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
 	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
 	"github.com/multiverse-vcs/go-git-ipfs/internal/view"
@@ -36,11 +38,16 @@ func (s *Auth) SignUpForm(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	err := (*core.Server)(s).SendEmailVerification(&user)
+	if err != nil && err != core.ErrMailDisabled {
+		log.Println(err)
+	}
+
 	sess := database.Session{
 		UserID: user.ID,
 	}
 
-	if err := session.Set(w, s.DB, &sess); err != nil {
+	if err := session.Set(w, req, s.DB, &sess); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+)
+
+var errVerifyToken = errors.New("verification link is invalid or expired")
+
+// VerifyEmail marks the email address the token was sent to as verified
+// if it is still the address of the user.
+func (s *Auth) VerifyEmail(w http.ResponseWriter, req *http.Request) {
+	var token database.UserToken
+	if err := token.FindBySecretAndPurpose(s.DB, mux.Vars(req)["token"], database.TokenVerifyEmail); err != nil {
+		http.Error(w, errVerifyToken.Error(), http.StatusNotFound)
+		return
+	}
+
+	if token.Email != token.User.Email {
+		http.Error(w, errVerifyToken.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := token.Consume(s.DB); err != nil {
+		http.Error(w, errVerifyToken.Error(), http.StatusNotFound)
+		return
+	}
+
+	token.User.EmailVerified = true
+	if err := token.User.UpdateEmailVerified(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := database.DeleteUserTokens(s.DB, token.UserID, database.TokenVerifyEmail); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, req, "/_settings", http.StatusSeeOther)
+}
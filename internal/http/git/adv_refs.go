@@ -10,6 +10,7 @@ import (
 	"github.com/gorilla/mux"
 	cid "github.com/ipfs/go-cid"
 
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
 	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
 )
 
@@ -34,13 +35,24 @@ func (s *Git) AdvertisedReferences(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	_, ok := s.authorize(w, req, &repo, service == transport.ReceivePackServiceName)
+	if !ok {
+		return
+	}
+
 	id, err := cid.Decode(repo.CID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	loader := NewLoader(ctx, s.Node.DAG, id)
+	key, err := (*core.Server)(s).RepoKey(&repo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	loader := NewLoader(ctx, s.Node.DAG, id, key)
 	server := server.NewServer(loader)
 
 	ep, err := transport.NewEndpoint(req.RequestURI)
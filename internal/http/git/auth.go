@@ -0,0 +1,74 @@
+package git
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+)
+
+var (
+	errCredentials = errors.New("invalid credentials")
+	errNotFound    = errors.New("repository not found")
+	errForbidden   = errors.New("push access denied")
+	errReadOnlyRef = errors.New("discussion ref is read only")
+)
+
+// authorize returns the user making the request if they can read the
+// repo, or write to it when write is true. An error response is written
+// otherwise and git clients are asked for credentials.
+//
+// A nil user is returned for anonymous requests.
+func (s *Git) authorize(w http.ResponseWriter, req *http.Request, repo *database.Repo, write bool) (*database.User, bool) {
+	user, err := s.credentials(req)
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", `Basic realm="multiverse"`)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return nil, false
+	}
+
+	allowed := repo.CanRead(s.DB, user)
+	if write {
+		allowed = repo.CanWrite(s.DB, user)
+	}
+
+	switch {
+	case allowed:
+		return user, true
+	case user == nil:
+		w.Header().Set("WWW-Authenticate", `Basic realm="multiverse"`)
+		http.Error(w, errCredentials.Error(), http.StatusUnauthorized)
+	case repo.CanRead(s.DB, user):
+		http.Error(w, errForbidden.Error(), http.StatusForbidden)
+	default:
+		http.Error(w, errNotFound.Error(), http.StatusNotFound)
+	}
+
+	return nil, false
+}
+
+// credentials returns the user from the basic auth credentials of the request.
+//
+// The password can be the account password or an API token.
+func (s *Git) credentials(req *http.Request) (*database.User, error) {
+	username, password, ok := req.BasicAuth()
+	if !ok {
+		return nil, nil
+	}
+
+	var user database.User
+	if err := user.FindByUsername(s.DB, username); err != nil {
+		return nil, errCredentials
+	}
+
+	if err := user.CheckPassword(password); err == nil {
+		return &user, nil
+	}
+
+	var token database.Token
+	if err := token.FindBySecret(s.DB, password); err != nil || token.UserID != user.ID {
+		return nil, errCredentials
+	}
+
+	return &user, nil
+}
@@ -8,7 +8,7 @@ import (
 	cid "github.com/ipfs/go-cid"
 	ipld "github.com/ipfs/go-ipld-format"
 
-	"github.com/multiverse-vcs/go-git-ipfs/pkg/storage"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/gitutil"
 	"github.com/multiverse-vcs/go-git-ipfs/pkg/storage/unixfs"
 )
 
@@ -17,15 +17,19 @@ type Loader struct {
 	ctx context.Context
 	ds  ipld.DAGService
 	id  cid.Cid
+	key []byte
 	fs  *unixfs.Unixfs
 }
 
 // NewLoader returns a new IPFS loader.
-func NewLoader(ctx context.Context, ds ipld.DAGService, id cid.Cid) *Loader {
+//
+// Repositories are decrypted with the key unless it is nil.
+func NewLoader(ctx context.Context, ds ipld.DAGService, id cid.Cid, key []byte) *Loader {
 	return &Loader{
 		ctx: ctx,
 		ds:  ds,
 		id:  id,
+		key: key,
 	}
 }
 
@@ -37,7 +41,7 @@ func (l *Loader) Load(ep *transport.Endpoint) (storer.Storer, error) {
 	}
 
 	l.fs = fs
-	return storage.NewStorage(fs), nil
+	return gitutil.NewStorage(fs, l.key)
 }
 
 // Node returns the final unixfs node.
@@ -1,7 +1,11 @@
 package git
 
 import (
+	"context"
+	"fmt"
+	"log"
 	"net/http"
+	"path"
 
 	"github.com/go-git/go-git/v5/plumbing/protocol/packp"
 	"github.com/go-git/go-git/v5/plumbing/transport"
@@ -9,7 +13,11 @@ import (
 	"github.com/gorilla/mux"
 	cid "github.com/ipfs/go-cid"
 
+	"github.com/multiverse-vcs/go-git-ipfs/internal/codesearch"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
 	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/pages"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/webhook"
 )
 
 // ReceivePack updates a repository with a packfile and replies with a status.
@@ -32,13 +40,33 @@ func (s *Git) ReceivePack(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	pusher, ok := s.authorize(w, req, &repo, true)
+	if !ok {
+		return
+	}
+
+	// the repo is loaded again after locking so its CID is current
+	unlock := (*core.Server)(s).LockRepo(repo.ID)
+	defer unlock()
+
+	if err := repo.Find(s.DB, repo.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	id, err := cid.Decode(repo.CID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	loader := NewLoader(ctx, s.Node.DAG, id)
+	key, err := (*core.Server)(s).RepoKey(&repo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	loader := NewLoader(ctx, s.Node.DAG, id, key)
 	server := server.NewServer(loader)
 
 	// acquire a pinlock so GC doesn't wipe out changes
@@ -71,6 +99,13 @@ func (s *Git) ReceivePack(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	for _, cmd := range sessreq.Commands {
+		if cmd.Name == core.DiscussionRef {
+			http.Error(w, errReadOnlyRef.Error(), http.StatusForbidden)
+			return
+		}
+	}
+
 	sessres, err := sess.ReceivePack(ctx, sessreq)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -94,8 +129,106 @@ func (s *Git) ReceivePack(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	for _, cmd := range sessreq.Commands {
+		if err := (*core.Server)(s).RecordPush(ctx, &repo, pusher.ID, cmd.Name, cmd.Old, cmd.New); err != nil {
+			log.Println(err)
+		}
+
+		if err := (*core.Server)(s).CloseIssues(ctx, &repo, cmd.Name, cmd.Old, cmd.New); err != nil {
+			log.Println(err)
+		}
+	}
+
+	// index in the background so large pushes are not delayed
+	go func(repo database.Repo) {
+		if err := codesearch.Index(context.Background(), s.DB, s.Node.DAG, &repo); err != nil {
+			log.Println(err)
+		}
+	}(repo)
+
+	// the push is already stored so a failed publish must not fail it
+	if repo.Pages {
+		if err := pages.Publish(ctx, (*core.Server)(s), &repo); err != nil {
+			log.Println(err)
+		}
+	}
+
+	var refs, deleted []webhook.Ref
+	for _, cmd := range sessreq.Commands {
+		ref := webhook.Ref{
+			Name: cmd.Name.String(),
+			Old:  cmd.Old.String(),
+			New:  cmd.New.String(),
+		}
+
+		if cmd.New.IsZero() && cmd.Name.IsBranch() {
+			deleted = append(deleted, ref)
+		}
+
+		refs = append(refs, ref)
+	}
+
+	payload := webhook.Payload{
+		Event: database.WebhookPushEvent,
+		Repository: webhook.Repository{
+			Owner: user.Username,
+			Name:  repo.Name,
+			CID:   repo.CID,
+		},
+		Pusher: pusher.Username,
+		Refs:   refs,
+	}
+
+	// the push succeeded so webhook errors are only logged
+	if err := webhook.Trigger(s.DB, s.Key, &repo, &payload); err != nil {
+		log.Println(err)
+	}
+
+	if len(deleted) > 0 {
+		payload.Event = database.WebhookDeleteEvent
+		payload.Refs = deleted
+
+		if err := webhook.Trigger(s.DB, s.Key, &repo, &payload); err != nil {
+			log.Println(err)
+		}
+	}
+
+	repo.User = user
+	for _, cmd := range sessreq.Commands {
+		notice := pushNotice(pusher, &repo, cmd)
+		if err := (*core.Server)(s).Notify(notice); err != nil {
+			log.Println(err)
+		}
+	}
+
 	w.Header().Add("Cache-Control", "no-cache")
 	w.Header().Add("Content-Type", "application/x-git-receive-pack-result")
 
 	sessres.Encode(w)
 }
+
+// pushNotice returns the notice for watchers of a ref update.
+func pushNotice(pusher *database.User, repo *database.Repo, cmd *packp.Command) *core.Notice {
+	link := path.Join("/", repo.User.Username, repo.Name)
+	name := cmd.Name.Short()
+
+	var title string
+	switch {
+	case cmd.New.IsZero():
+		title = fmt.Sprintf("%s deleted %s", pusher.Username, name)
+	case cmd.Old.IsZero():
+		title = fmt.Sprintf("%s created %s", pusher.Username, name)
+		link = path.Join(link, "tree", cmd.New.String())
+	default:
+		title = fmt.Sprintf("%s pushed to %s", pusher.Username, name)
+		link = path.Join(link, "tree", cmd.New.String())
+	}
+
+	return &core.Notice{
+		Actor: pusher,
+		Repo:  repo,
+		Type:  database.NotificationPush,
+		Title: title,
+		Link:  link,
+	}
+}
@@ -4,4 +4,12 @@ import (
 	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
 )
 
+// HomeResultsPerPage is the amount of search results per page.
+const HomeResultsPerPage = 20
+
+const (
+	HomeReposTab = "repos"
+	HomeUsersTab = "users"
+)
+
 type Home core.Server
@@ -2,6 +2,7 @@ package home
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
 	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
@@ -16,12 +17,53 @@ func (s *Home) Read(w http.ResponseWriter, req *http.Request) {
 		data["Session"] = sess
 	}
 
-	var repos []database.Repo
-	if err := s.DB.Limit(10).Order("updated_at desc").Preload("User").Find(&repos).Error; err != nil {
+	query := req.URL.Query()
+	text := query.Get("q")
+	sort := query.Get("sort")
+	tab := query.Get("tab")
+	offset := query.Get("offset")
+
+	if offset == "" {
+		offset = "0"
+	}
+
+	if _, ok := database.RepoSortOrders[sort]; !ok {
+		sort = "updated"
+	}
+
+	offsetnum, err := strconv.ParseInt(offset, 10, 64)
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	data["Repos"] = repos
+	switch tab {
+	case HomeUsersTab:
+		users, err := database.SearchUsers(s.DB, text, int(offsetnum), HomeResultsPerPage)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		data["Users"] = users
+		data["Count"] = len(users)
+	default:
+		repos, err := database.SearchRepos(s.DB.Scopes(database.VisibleRepos(sess.Viewer())), text, sort, int(offsetnum), HomeResultsPerPage)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		tab = HomeReposTab
+		data["Repos"] = repos
+		data["Count"] = len(repos)
+	}
+
+	data["Query"] = text
+	data["Sort"] = sort
+	data["Tab"] = tab
+	data["Limit"] = HomeResultsPerPage
+	data["Next"] = offsetnum + HomeResultsPerPage
+	data["Prev"] = offsetnum - HomeResultsPerPage
 	view.Render(w, "home.html", data)
 }
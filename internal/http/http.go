@@ -6,39 +6,165 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/api"
 	"github.com/multiverse-vcs/go-git-ipfs/internal/http/auth"
 	"github.com/multiverse-vcs/go-git-ipfs/internal/http/git"
 	"github.com/multiverse-vcs/go-git-ipfs/internal/http/home"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/notification"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/org"
 	"github.com/multiverse-vcs/go-git-ipfs/internal/http/repo"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/search"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/settings"
 	"github.com/multiverse-vcs/go-git-ipfs/internal/http/user"
 	"github.com/multiverse-vcs/go-git-ipfs/web"
 )
 
 // NewServer returns a new http server.
 func NewServer(server *core.Server) *http.Server {
+	api := (*api.API)(server)
 	auth := (*auth.Auth)(server)
 	git := (*git.Git)(server)
 	home := (*home.Home)(server)
+	notification := (*notification.Notification)(server)
+	org := (*org.Org)(server)
 	repo := (*repo.Repo)(server)
+	search := (*search.Search)(server)
+	settings := (*settings.Settings)(server)
 	user := (*user.User)(server)
 
 	static := http.FileServer(http.FS(web.Public))
 	router := mux.NewRouter()
+	router.Use(secureHeaders)
+	router.Use(repoRedirects(server.DB))
+	router.Use(renewSessions(server.DB))
+
+	v1 := router.PathPrefix("/api/v1").Subrouter()
+	v1.NotFoundHandler = http.HandlerFunc(api.NotFound)
+	v1.MethodNotAllowedHandler = http.HandlerFunc(api.MethodNotAllowed)
+	v1.HandleFunc("/openapi.yaml", api.OpenAPI).Methods(http.MethodGet)
+	v1.HandleFunc("/user", api.CurrentUser).Methods(http.MethodGet)
+	v1.HandleFunc("/user/tokens", api.ListTokens).Methods(http.MethodGet)
+	v1.HandleFunc("/user/tokens", api.CreateToken).Methods(http.MethodPost)
+	v1.HandleFunc("/user/tokens/{id:[0-9]+}", api.DeleteToken).Methods(http.MethodDelete)
+	v1.HandleFunc("/users/{user}", api.ReadUser).Methods(http.MethodGet)
+	v1.HandleFunc("/users/{user}/repos", api.UserRepos).Methods(http.MethodGet)
+	v1.HandleFunc("/repos", api.ListRepos).Methods(http.MethodGet)
+	v1.HandleFunc("/repos", api.CreateRepo).Methods(http.MethodPost)
+	v1.HandleFunc("/repos/{user}/{repo}", api.ReadRepo).Methods(http.MethodGet)
+	v1.HandleFunc("/repos/{user}/{repo}/forks", api.Forks).Methods(http.MethodGet)
+	v1.HandleFunc("/repos/{user}/{repo}/forks", api.CreateFork).Methods(http.MethodPost)
+	v1.HandleFunc("/repos/{user}/{repo}/branches", api.Branches).Methods(http.MethodGet)
+	v1.HandleFunc("/repos/{user}/{repo}/tags", api.Tags).Methods(http.MethodGet)
+	v1.HandleFunc("/repos/{user}/{repo}/commits", api.Commits).Methods(http.MethodGet)
+	v1.HandleFunc("/repos/{user}/{repo}/commits/{hash}", api.ReadCommit).Methods(http.MethodGet)
+	v1.HandleFunc("/repos/{user}/{repo}/tree", api.Tree).Methods(http.MethodGet)
+	v1.HandleFunc("/repos/{user}/{repo}/tree/{refpath:.*}", api.Tree).Methods(http.MethodGet)
+	v1.HandleFunc("/repos/{user}/{repo}/blob/{refpath:.*}", api.Blob).Methods(http.MethodGet)
+
 	router.HandleFunc("/", home.Read).Methods(http.MethodGet)
 	router.PathPrefix("/public/").Handler(static)
 	router.HandleFunc("/_create_repo", repo.Create).Methods(http.MethodGet)
 	router.HandleFunc("/_create_repo", repo.CreateForm).Methods(http.MethodPost)
+	router.HandleFunc("/_create_org", org.Create).Methods(http.MethodGet)
+	router.HandleFunc("/_create_org", org.CreateForm).Methods(http.MethodPost)
+	router.HandleFunc("/_search", search.Read).Methods(http.MethodGet)
+	router.HandleFunc("/_notifications", notification.Read).Methods(http.MethodGet)
+	router.HandleFunc("/_notifications/read", notification.MarkAllRead).Methods(http.MethodPost)
+	router.HandleFunc("/_notifications/{id:[0-9]+}", notification.Open).Methods(http.MethodGet)
+	router.HandleFunc("/_notifications/{id:[0-9]+}/read", notification.MarkRead).Methods(http.MethodPost)
+	router.HandleFunc("/_settings", settings.Read).Methods(http.MethodGet)
+	router.HandleFunc("/_settings/email", settings.EditEmail).Methods(http.MethodPost)
+	router.HandleFunc("/_settings/email/verify", settings.ResendVerification).Methods(http.MethodPost)
+	router.HandleFunc("/_settings/notifications", settings.EditNotifications).Methods(http.MethodPost)
+	router.HandleFunc("/_settings/profile", settings.EditProfile).Methods(http.MethodPost)
+	router.HandleFunc("/_settings/avatar", settings.EditAvatar).Methods(http.MethodPost)
+	router.HandleFunc("/_settings/sessions", settings.Sessions).Methods(http.MethodGet)
+	router.HandleFunc("/_settings/sessions/delete", settings.RevokeOtherSessions).Methods(http.MethodPost)
+	router.HandleFunc("/_settings/sessions/{id:[0-9a-f]+}/delete", settings.RevokeSession).Methods(http.MethodPost)
+	router.HandleFunc("/_settings/webhooks", settings.CreateWebhook).Methods(http.MethodPost)
+	router.HandleFunc("/_settings/webhooks/{hook:[0-9]+}/delete", settings.DeleteWebhook).Methods(http.MethodPost)
+	router.HandleFunc("/_settings/webhooks/{hook:[0-9]+}/deliveries/{id:[0-9]+}/redeliver", settings.Redeliver).Methods(http.MethodPost)
+	router.HandleFunc("/_settings/transfers/{id:[0-9]+}/accept", settings.AcceptTransfer).Methods(http.MethodPost)
+	router.HandleFunc("/_settings/transfers/{id:[0-9]+}/decline", settings.DeclineTransfer).Methods(http.MethodPost)
 	router.HandleFunc("/_sign_up", auth.SignUp).Methods(http.MethodGet)
 	router.HandleFunc("/_sign_up", auth.SignUpForm).Methods(http.MethodPost)
 	router.HandleFunc("/_log_in", auth.LogIn).Methods(http.MethodGet)
 	router.HandleFunc("/_log_in", auth.LogInForm).Methods(http.MethodPost)
-	router.HandleFunc("/_log_out", auth.LogOut).Methods(http.MethodGet)
+	router.HandleFunc("/_log_out", auth.LogOut).Methods(http.MethodPost)
+	router.HandleFunc("/_password_reset", auth.PasswordReset).Methods(http.MethodGet)
+	router.HandleFunc("/_password_reset", auth.PasswordResetForm).Methods(http.MethodPost)
+	router.HandleFunc("/_password_reset/{token}", auth.NewPassword).Methods(http.MethodGet)
+	router.HandleFunc("/_password_reset/{token}", auth.NewPasswordForm).Methods(http.MethodPost)
+	router.HandleFunc("/_verify_email/{token}", auth.VerifyEmail).Methods(http.MethodGet)
+	router.HandleFunc("/{user}.atom", user.Feed).Methods(http.MethodGet)
 	router.HandleFunc("/{user}", user.Read).Methods(http.MethodGet)
+	router.HandleFunc("/{user}/_avatar", user.Avatar).Methods(http.MethodGet)
+	router.HandleFunc("/{user}/_settings", org.Settings).Methods(http.MethodGet)
+	router.HandleFunc("/{user}/_settings/members", org.AddMember).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/_settings/members/{id:[0-9]+}/delete", org.RemoveMember).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/_settings/teams", org.CreateTeam).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/_teams/{team}", org.Team).Methods(http.MethodGet)
+	router.HandleFunc("/{user}/_teams/{team}/delete", org.DeleteTeam).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/_teams/{team}/members", org.AddTeamMember).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/_teams/{team}/members/{id:[0-9]+}/delete", org.RemoveTeamMember).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/_teams/{team}/repos", org.AddTeamRepo).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/_teams/{team}/repos/{id:[0-9]+}/delete", org.RemoveTeamRepo).Methods(http.MethodPost)
 	router.HandleFunc("/{user}/{repo}", repo.Read).Methods(http.MethodGet)
 	router.HandleFunc("/{user}/{repo}/tree", repo.Tree).Methods(http.MethodGet)
 	router.HandleFunc("/{user}/{repo}/tree/{refpath:.*}", repo.Tree).Methods(http.MethodGet)
+	router.HandleFunc("/{user}/{repo}/raw/{refpath:.*}", repo.Raw).Methods(http.MethodGet)
 	router.HandleFunc("/{user}/{repo}/logs", repo.Logs).Methods(http.MethodGet)
+	router.HandleFunc("/{user}/{repo}/logs.atom", repo.CommitsFeed).Methods(http.MethodGet)
 	router.HandleFunc("/{user}/{repo}/refs", repo.Refs).Methods(http.MethodGet)
+	router.HandleFunc("/{user}/{repo}/tags.atom", repo.TagsFeed).Methods(http.MethodGet)
+	router.HandleFunc("/{user}/{repo}/releases", repo.Releases).Methods(http.MethodGet)
+	router.HandleFunc("/{user}/{repo}/releases", repo.CreateRelease).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/releases.atom", repo.ReleasesFeed).Methods(http.MethodGet)
+	router.HandleFunc("/{user}/{repo}/releases/{id:[0-9]+}/delete", repo.DeleteRelease).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/releases/{id:[0-9]+}/assets", repo.UploadAssets).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/releases/{id:[0-9]+}/assets/{name}", repo.Asset).Methods(http.MethodGet)
+	router.HandleFunc("/{user}/{repo}/releases/{id:[0-9]+}/assets/{name}/delete", repo.DeleteAsset).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/search", repo.Search).Methods(http.MethodGet)
+	router.HandleFunc("/{user}/{repo}/forks", repo.Forks).Methods(http.MethodGet)
+	router.HandleFunc("/{user}/{repo}/fork", repo.Fork).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/star", repo.Star).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/unstar", repo.Unstar).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/watch", repo.Watch).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/unwatch", repo.Unwatch).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/issues", repo.Issues).Methods(http.MethodGet)
+	router.HandleFunc("/{user}/{repo}/issues", repo.CreateIssue).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/issues/new", repo.NewIssue).Methods(http.MethodGet)
+	router.HandleFunc("/{user}/{repo}/issues/labels", repo.CreateLabel).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/issues/labels/{id:[0-9]+}/delete", repo.DeleteLabel).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/issues/{number:[0-9]+}", repo.Issue).Methods(http.MethodGet)
+	router.HandleFunc("/{user}/{repo}/issues/{number:[0-9]+}/comments", repo.CommentIssue).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/issues/{number:[0-9]+}/close", repo.CloseIssue).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/issues/{number:[0-9]+}/reopen", repo.ReopenIssue).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/issues/{number:[0-9]+}/labels", repo.EditIssueLabels).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/issues/{number:[0-9]+}/assignees", repo.AddAssignee).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/issues/{number:[0-9]+}/assignees/{id:[0-9]+}/delete", repo.RemoveAssignee).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/pulls", repo.Pulls).Methods(http.MethodGet)
+	router.HandleFunc("/{user}/{repo}/pulls", repo.CreatePull).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/pulls/new", repo.NewPull).Methods(http.MethodGet)
+	router.HandleFunc("/{user}/{repo}/pulls/{number:[0-9]+}", repo.Pull).Methods(http.MethodGet)
+	router.HandleFunc("/{user}/{repo}/pulls/{number:[0-9]+}/comments", repo.CommentPull).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/pulls/{number:[0-9]+}/reviews", repo.ReviewPull).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/pulls/{number:[0-9]+}/merge", repo.MergePull).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/pulls/{number:[0-9]+}/close", repo.ClosePull).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/pulls/{number:[0-9]+}/reopen", repo.ReopenPull).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/settings", repo.Settings).Methods(http.MethodGet)
+	router.HandleFunc("/{user}/{repo}/settings/description", repo.EditDescription).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/settings/visibility", repo.EditVisibility).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/settings/rename", repo.Rename).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/settings/transfer", repo.Transfer).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/settings/transfer/cancel", repo.CancelTransfer).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/settings/delete", repo.Delete).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/settings/collaborators", repo.AddCollaborator).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/settings/collaborators/{id:[0-9]+}/delete", repo.RemoveCollaborator).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/settings/webhooks", repo.CreateWebhook).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/settings/webhooks/{hook:[0-9]+}/delete", repo.DeleteWebhook).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/settings/webhooks/{hook:[0-9]+}/deliveries/{id:[0-9]+}/redeliver", repo.Redeliver).Methods(http.MethodPost)
+	router.HandleFunc("/{user}/{repo}/ipfs/{refpath:.*}", repo.Pages).Methods(http.MethodGet)
 	router.HandleFunc("/{user}/{repo}/git-upload-pack", git.UploadPack).Methods(http.MethodPost)
 	router.HandleFunc("/{user}/{repo}/git-receive-pack", git.ReceivePack).Methods(http.MethodPost)
 	router.HandleFunc("/{user}/{repo}/info/refs", git.AdvertisedReferences).Methods(http.MethodGet)
@@ -0,0 +1,15 @@
+package notification
+
+import (
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
+)
+
+// NotificationsPerPage is the amount of notifications shown in the inbox.
+const NotificationsPerPage = 50
+
+const (
+	NotificationUnreadTab = "unread"
+	NotificationAllTab    = "all"
+)
+
+type Notification core.Server
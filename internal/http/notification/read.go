@@ -0,0 +1,102 @@
+package notification
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/view"
+)
+
+func (s *Notification) Read(w http.ResponseWriter, req *http.Request) {
+	data := make(map[string]interface{})
+
+	sess, err := session.Get(req, s.DB)
+	if err != nil {
+		http.Redirect(w, req, "/_log_in", http.StatusSeeOther)
+		return
+	}
+
+	tab := req.URL.Query().Get("tab")
+	if tab != NotificationAllTab {
+		tab = NotificationUnreadTab
+	}
+
+	notifications, err := database.FindNotificationsByUserID(s.DB, sess.UserID, tab == NotificationAllTab, NotificationsPerPage)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	unread, err := database.CountUnreadNotifications(s.DB, sess.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data["Session"] = sess
+	data["Notifications"] = notifications
+	data["Unread"] = unread
+	data["Tab"] = tab
+	view.Render(w, "notifications.html", data)
+}
+
+// Open marks the notification as read and redirects to the page showing its event.
+func (s *Notification) Open(w http.ResponseWriter, req *http.Request) {
+	sess, err := session.Get(req, s.DB)
+	if err != nil {
+		http.Redirect(w, req, "/_log_in", http.StatusSeeOther)
+		return
+	}
+
+	var notification database.Notification
+	if err := notification.FindByIDAndUserID(s.DB, mux.Vars(req)["id"], sess.UserID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := notification.MarkRead(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, req, notification.Link, http.StatusSeeOther)
+}
+
+func (s *Notification) MarkRead(w http.ResponseWriter, req *http.Request) {
+	sess, err := session.Get(req, s.DB)
+	if err != nil {
+		http.Redirect(w, req, "/_log_in", http.StatusSeeOther)
+		return
+	}
+
+	var notification database.Notification
+	if err := notification.FindByIDAndUserID(s.DB, mux.Vars(req)["id"], sess.UserID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := notification.MarkRead(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, req, "/_notifications", http.StatusSeeOther)
+}
+
+func (s *Notification) MarkAllRead(w http.ResponseWriter, req *http.Request) {
+	sess, err := session.Get(req, s.DB)
+	if err != nil {
+		http.Redirect(w, req, "/_log_in", http.StatusSeeOther)
+		return
+	}
+
+	if err := database.MarkNotificationsRead(s.DB, sess.UserID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, req, "/_notifications", http.StatusSeeOther)
+}
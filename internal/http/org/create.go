@@ -0,0 +1,51 @@
+package org
+
+import (
+	"net/http"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/view"
+)
+
+func (s *Org) Create(w http.ResponseWriter, req *http.Request) {
+	data := make(map[string]interface{})
+
+	sess, err := session.Get(req, s.DB)
+	if err != nil {
+		http.Redirect(w, req, "/_log_in", http.StatusSeeOther)
+		return
+	}
+
+	data["Session"] = sess
+	view.Render(w, "create_org.html", data)
+}
+
+func (s *Org) CreateForm(w http.ResponseWriter, req *http.Request) {
+	name := req.FormValue("name")
+	email := req.FormValue("email")
+
+	sess, err := session.Get(req, s.DB)
+	if err != nil {
+		http.Redirect(w, req, "/_log_in", http.StatusSeeOther)
+		return
+	}
+
+	var org database.User
+	if err := org.FindByEmailOrUsername(s.DB, email, name); err == nil {
+		http.Error(w, errTaken.Error(), http.StatusBadRequest)
+		return
+	}
+
+	org = database.User{
+		Username: name,
+		Email:    email,
+	}
+
+	if err := database.CreateOrg(s.DB, &org, sess.UserID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, req, "/"+org.Username, http.StatusSeeOther)
+}
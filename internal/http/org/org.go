@@ -0,0 +1,63 @@
+package org
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
+)
+
+var (
+	errNotOrg      = errors.New("user is not an organization")
+	errNotOrgOwner = errors.New("only organization owners can change organization settings")
+	errLastOwner   = errors.New("organizations must have at least one owner")
+	errNotMember   = errors.New("user is not a member of the organization")
+	errOrgMember   = errors.New("organizations cannot be members")
+	errTaken       = errors.New("username or email is already taken")
+)
+
+type Org core.Server
+
+// findOwnedOrg returns the session and organization of the request.
+//
+// An error response is written if the session user is not an owner.
+func (s *Org) findOwnedOrg(w http.ResponseWriter, req *http.Request) (*database.Session, *database.User, bool) {
+	sess, err := session.Get(req, s.DB)
+	if err != nil {
+		http.Redirect(w, req, "/_log_in", http.StatusSeeOther)
+		return nil, nil, false
+	}
+
+	var org database.User
+	if err := org.FindByUsername(s.DB, mux.Vars(req)["user"]); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return nil, nil, false
+	}
+
+	if !org.Org {
+		http.Error(w, errNotOrg.Error(), http.StatusNotFound)
+		return nil, nil, false
+	}
+
+	if !database.IsOrgOwner(s.DB, org.ID, sess.UserID) {
+		http.Error(w, errNotOrgOwner.Error(), http.StatusForbidden)
+		return nil, nil, false
+	}
+
+	return sess, &org, true
+}
+
+// findTeam returns the team of the request in the organization.
+func (s *Org) findTeam(w http.ResponseWriter, req *http.Request, org *database.User) (*database.Team, bool) {
+	var team database.Team
+	if err := team.FindByNameAndOrgID(s.DB, mux.Vars(req)["team"], org.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return nil, false
+	}
+
+	return &team, true
+}
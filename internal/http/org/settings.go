@@ -0,0 +1,119 @@
+package org
+
+import (
+	"errors"
+	"net/http"
+	"path"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/view"
+)
+
+func (s *Org) Settings(w http.ResponseWriter, req *http.Request) {
+	data := make(map[string]interface{})
+
+	sess, org, ok := s.findOwnedOrg(w, req)
+	if !ok {
+		return
+	}
+
+	members, err := database.FindMembersByOrgID(s.DB, org.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	teams, err := database.FindTeamsByOrgID(s.DB, org.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data["Session"] = sess
+	data["User"] = org
+	data["Members"] = members
+	data["Teams"] = teams
+	data["Roles"] = database.OrgRoles
+	view.Render(w, "org_settings.html", data)
+}
+
+func (s *Org) AddMember(w http.ResponseWriter, req *http.Request) {
+	username := req.FormValue("username")
+	role := req.FormValue("role")
+
+	_, org, ok := s.findOwnedOrg(w, req)
+	if !ok {
+		return
+	}
+
+	var user database.User
+	if err := user.FindByUsername(s.DB, username); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if user.Org {
+		http.Error(w, errOrgMember.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// adding an existing member changes their role
+	var member database.Member
+	err := member.FindByOrgIDAndUserID(s.DB, org.ID, user.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if member.Role == database.OrgOwner && role != database.OrgOwner && !s.hasOtherOwner(org) {
+		http.Error(w, errLastOwner.Error(), http.StatusBadRequest)
+		return
+	}
+
+	member.OrgID = org.ID
+	member.UserID = user.ID
+	member.Role = role
+
+	if err := member.Save(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	url := path.Join("/", org.Username, "_settings")
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Org) RemoveMember(w http.ResponseWriter, req *http.Request) {
+	_, org, ok := s.findOwnedOrg(w, req)
+	if !ok {
+		return
+	}
+
+	var member database.Member
+	if err := member.FindByIDAndOrgID(s.DB, mux.Vars(req)["id"], org.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if member.Role == database.OrgOwner && !s.hasOtherOwner(org) {
+		http.Error(w, errLastOwner.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := member.Delete(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	url := path.Join("/", org.Username, "_settings")
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+// hasOtherOwner returns true if an owner remains after one is removed.
+func (s *Org) hasOtherOwner(org *database.User) bool {
+	count, err := database.CountOrgOwners(s.DB, org.ID)
+	return err == nil && count > 1
+}
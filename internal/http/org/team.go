@@ -0,0 +1,221 @@
+package org
+
+import (
+	"errors"
+	"net/http"
+	"path"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/view"
+)
+
+func (s *Org) Team(w http.ResponseWriter, req *http.Request) {
+	data := make(map[string]interface{})
+
+	sess, org, ok := s.findOwnedOrg(w, req)
+	if !ok {
+		return
+	}
+
+	team, ok := s.findTeam(w, req, org)
+	if !ok {
+		return
+	}
+
+	members, err := database.FindTeamMembersByTeamID(s.DB, team.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	repos, err := database.FindTeamReposByTeamID(s.DB, team.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data["Session"] = sess
+	data["User"] = org
+	data["Team"] = team
+	data["Members"] = members
+	data["Repos"] = repos
+	data["Roles"] = database.CollaboratorRoles
+	view.Render(w, "team.html", data)
+}
+
+func (s *Org) CreateTeam(w http.ResponseWriter, req *http.Request) {
+	name := req.FormValue("name")
+
+	_, org, ok := s.findOwnedOrg(w, req)
+	if !ok {
+		return
+	}
+
+	team := database.Team{
+		OrgID: org.ID,
+		Name:  name,
+	}
+
+	if err := team.Create(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	url := path.Join("/", org.Username, "_teams", team.Name)
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Org) DeleteTeam(w http.ResponseWriter, req *http.Request) {
+	_, org, ok := s.findOwnedOrg(w, req)
+	if !ok {
+		return
+	}
+
+	team, ok := s.findTeam(w, req, org)
+	if !ok {
+		return
+	}
+
+	if err := team.Delete(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	url := path.Join("/", org.Username, "_settings")
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Org) AddTeamMember(w http.ResponseWriter, req *http.Request) {
+	username := req.FormValue("username")
+
+	_, org, ok := s.findOwnedOrg(w, req)
+	if !ok {
+		return
+	}
+
+	team, ok := s.findTeam(w, req, org)
+	if !ok {
+		return
+	}
+
+	var user database.User
+	if err := user.FindByUsername(s.DB, username); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var member database.Member
+	if err := member.FindByOrgIDAndUserID(s.DB, org.ID, user.ID); err != nil {
+		http.Error(w, errNotMember.Error(), http.StatusBadRequest)
+		return
+	}
+
+	teamMember := database.TeamMember{
+		TeamID: team.ID,
+		UserID: user.ID,
+	}
+
+	if err := teamMember.Create(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	url := path.Join("/", org.Username, "_teams", team.Name)
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Org) RemoveTeamMember(w http.ResponseWriter, req *http.Request) {
+	_, org, ok := s.findOwnedOrg(w, req)
+	if !ok {
+		return
+	}
+
+	team, ok := s.findTeam(w, req, org)
+	if !ok {
+		return
+	}
+
+	var member database.TeamMember
+	if err := member.FindByIDAndTeamID(s.DB, mux.Vars(req)["id"], team.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := member.Delete(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	url := path.Join("/", org.Username, "_teams", team.Name)
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Org) AddTeamRepo(w http.ResponseWriter, req *http.Request) {
+	name := req.FormValue("name")
+	role := req.FormValue("role")
+
+	_, org, ok := s.findOwnedOrg(w, req)
+	if !ok {
+		return
+	}
+
+	team, ok := s.findTeam(w, req, org)
+	if !ok {
+		return
+	}
+
+	var repo database.Repo
+	if err := repo.FindByNameAndUserID(s.DB, name, org.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// adding an existing repo changes its role
+	var grant database.TeamRepo
+	err := grant.FindByTeamIDAndRepoID(s.DB, team.ID, repo.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	grant.TeamID = team.ID
+	grant.RepoID = repo.ID
+	grant.Role = role
+
+	if err := grant.Save(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	url := path.Join("/", org.Username, "_teams", team.Name)
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Org) RemoveTeamRepo(w http.ResponseWriter, req *http.Request) {
+	_, org, ok := s.findOwnedOrg(w, req)
+	if !ok {
+		return
+	}
+
+	team, ok := s.findTeam(w, req, org)
+	if !ok {
+		return
+	}
+
+	var grant database.TeamRepo
+	if err := grant.FindByIDAndTeamID(s.DB, mux.Vars(req)["id"], team.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := grant.Delete(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	url := path.Join("/", org.Username, "_teams", team.Name)
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
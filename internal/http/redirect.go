@@ -0,0 +1,75 @@
+package http
+
+import (
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+)
+
+// repoRedirects redirects requests for renamed or transferred
+// repositories to their current location.
+//
+// Redirects are only used when no repo exists at the requested path.
+func repoRedirects(db *gorm.DB) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			params := mux.Vars(req)
+			username, reponame := params["user"], params["repo"]
+
+			if username == "" || reponame == "" {
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			// a repo living at the path wins over stale redirects
+			if repoExists(db, username, reponame) {
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			var redirect database.Redirect
+			if err := redirect.FindByUsernameAndName(db, username, reponame); err != nil {
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			var repo database.Repo
+			if err := db.Preload("User").First(&repo, redirect.RepoID).Error; err != nil {
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			old := path.Join("/", username, reponame)
+			cur := path.Join("/", repo.User.Username, repo.Name)
+
+			i := strings.Index(req.URL.Path, old)
+			if i < 0 {
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			url := *req.URL
+			url.Path = req.URL.Path[:i] + cur + req.URL.Path[i+len(old):]
+			url.RawPath = ""
+
+			// temporary redirects keep the request method for git pushes
+			http.Redirect(w, req, url.String(), http.StatusTemporaryRedirect)
+		})
+	}
+}
+
+// repoExists returns true if the user with the username owns a repo with the name.
+func repoExists(db *gorm.DB, username, reponame string) bool {
+	var user database.User
+	if err := user.FindByUsername(db, username); err != nil {
+		return false
+	}
+
+	var repo database.Repo
+	return repo.FindByNameAndUserID(db, reponame, user.ID) == nil
+}
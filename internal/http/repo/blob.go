@@ -0,0 +1,86 @@
+package repo
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+const (
+	// BlobHighlightLimit is the largest blob size that is syntax highlighted.
+	BlobHighlightLimit = 512 * 1024
+	// BlobPreviewLimit is the amount of a large blob that is displayed.
+	BlobPreviewLimit = 64 * 1024
+	// BlobSniffLimit is the amount of a blob inspected to detect binary content.
+	BlobSniffLimit = 8000
+)
+
+const (
+	BlobTextKind   = "text"
+	BlobLargeKind  = "large"
+	BlobImageKind  = "image"
+	BlobAudioKind  = "audio"
+	BlobVideoKind  = "video"
+	BlobPDFKind    = "pdf"
+	BlobBinaryKind = "binary"
+)
+
+// blobKind returns how the blob with the given name should be displayed.
+func blobKind(name string, blob *object.Blob) (string, error) {
+	ctype := mime.TypeByExtension(path.Ext(name))
+	switch {
+	case strings.HasPrefix(ctype, "image/"):
+		return BlobImageKind, nil
+	case strings.HasPrefix(ctype, "audio/"):
+		return BlobAudioKind, nil
+	case strings.HasPrefix(ctype, "video/"):
+		return BlobVideoKind, nil
+	case ctype == "application/pdf":
+		return BlobPDFKind, nil
+	}
+
+	head, err := blobHead(blob, BlobSniffLimit)
+	if err != nil {
+		return "", err
+	}
+
+	// git uses the same heuristic to detect binary files
+	if bytes.IndexByte(head, 0) >= 0 {
+		return BlobBinaryKind, nil
+	}
+
+	if blob.Size > BlobHighlightLimit {
+		return BlobLargeKind, nil
+	}
+
+	return BlobTextKind, nil
+}
+
+// blobPreview returns the leading lines of a large text blob.
+func blobPreview(blob *object.Blob) (string, error) {
+	head, err := blobHead(blob, BlobPreviewLimit)
+	if err != nil {
+		return "", err
+	}
+
+	if i := bytes.LastIndexByte(head, '\n'); i > 0 {
+		head = head[:i+1]
+	}
+
+	return string(head), nil
+}
+
+// blobHead returns up to limit bytes from the start of the blob.
+func blobHead(blob *object.Blob, limit int64) ([]byte, error) {
+	r, err := blob.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(io.LimitReader(r, limit))
+}
@@ -0,0 +1,76 @@
+package repo
+
+import (
+	"errors"
+	"net/http"
+	"path"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+)
+
+var errCollaboratorOwner = errors.New("the owner cannot be a collaborator")
+
+func (s *Repo) AddCollaborator(w http.ResponseWriter, req *http.Request) {
+	username := req.FormValue("username")
+	role := req.FormValue("role")
+
+	_, user, repo, ok := s.findRepoWithRole(w, req, database.RoleAdmin)
+	if !ok {
+		return
+	}
+
+	var member database.User
+	if err := member.FindByUsername(s.DB, username); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if member.ID == repo.UserID {
+		http.Error(w, errCollaboratorOwner.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// adding an existing collaborator changes their role
+	var collaborator database.Collaborator
+	err := collaborator.FindByRepoIDAndUserID(s.DB, repo.ID, member.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	collaborator.RepoID = repo.ID
+	collaborator.UserID = member.ID
+	collaborator.Role = role
+
+	if err := collaborator.Save(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	url := path.Join("/", user.Username, repo.Name, "settings")
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Repo) RemoveCollaborator(w http.ResponseWriter, req *http.Request) {
+	_, user, repo, ok := s.findRepoWithRole(w, req, database.RoleAdmin)
+	if !ok {
+		return
+	}
+
+	var collaborator database.Collaborator
+	if err := collaborator.FindByIDAndRepoID(s.DB, mux.Vars(req)["id"], repo.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := collaborator.Delete(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	url := path.Join("/", user.Username, repo.Name, "settings")
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
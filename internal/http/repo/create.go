@@ -2,22 +2,33 @@ package repo
 
 import (
 	"fmt"
+	"log"
 	"net/http"
 
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
 	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
-	"github.com/multiverse-vcs/go-git-ipfs/internal/gitutil"
 	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
 	"github.com/multiverse-vcs/go-git-ipfs/internal/view"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/webhook"
 )
 
 func (s *Repo) Create(w http.ResponseWriter, req *http.Request) {
 	data := make(map[string]interface{})
 
 	sess, err := session.Get(req, s.DB)
-	if err == nil {
-		data["Session"] = sess
+	if err != nil {
+		http.Redirect(w, req, "/_log_in", http.StatusSeeOther)
+		return
 	}
 
+	orgs, err := database.FindOrgsByUserID(s.DB, sess.UserID, database.OrgOwner)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data["Session"] = sess
+	data["Orgs"] = orgs
 	view.Render(w, "create_repo.html", data)
 }
 
@@ -25,6 +36,10 @@ func (s *Repo) CreateForm(w http.ResponseWriter, req *http.Request) {
 	ctx := req.Context()
 	name := req.FormValue("name")
 	description := req.FormValue("description")
+	pages := req.FormValue("pages") == "on"
+	visibility := req.FormValue("visibility")
+	encrypted := req.FormValue("encrypted") == "on"
+	username := req.FormValue("owner")
 
 	sess, err := session.Get(req, s.DB)
 	if err != nil {
@@ -32,8 +47,25 @@ func (s *Repo) CreateForm(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	// repos can be created for organizations the user owns
+	owner := &sess.User
+	if username != "" && username != owner.Username {
+		var org database.User
+		if err := org.FindByUsername(s.DB, username); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if !org.Org || !database.IsOrgOwner(s.DB, org.ID, sess.UserID) {
+			http.Error(w, errOwner.Error(), http.StatusForbidden)
+			return
+		}
+
+		owner = &org
+	}
+
 	var repo database.Repo
-	if err := repo.FindByNameAndUserID(s.DB, name, sess.UserID); err == nil {
+	if err := repo.FindByNameAndUserID(s.DB, name, owner.ID); err == nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -41,29 +73,37 @@ func (s *Repo) CreateForm(w http.ResponseWriter, req *http.Request) {
 	// acquire a pinlock so GC doesn't wipe out changes
 	defer s.Node.Blockstore.PinLock().Unlock()
 
-	node, err := gitutil.Init(ctx, s.Node.DAG)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	repo = database.Repo{
+		Name:        name,
+		Description: description,
+		UserID:      owner.ID,
+		Pages:       pages,
+		Visibility:  visibility,
+		Encrypted:   encrypted,
 	}
 
-	if err := s.Node.Pinning.Pin(ctx, node, true); err != nil {
+	if err := (*core.Server)(s).CreateRepo(ctx, &repo); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	repo = database.Repo{
-		Name:        name,
-		Description: description,
-		UserID:      sess.UserID,
-		CID:         node.Cid().String(),
+	if err := (*core.Server)(s).RecordActivity(sess.UserID, &repo, database.ActivityCreate); err != nil {
+		log.Println(err)
 	}
 
-	if err := repo.Create(s.DB); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	payload := webhook.Payload{
+		Event: database.WebhookCreateEvent,
+		Repository: webhook.Repository{
+			Owner: owner.Username,
+			Name:  repo.Name,
+			CID:   repo.CID,
+		},
+	}
+
+	if err := webhook.Trigger(s.DB, s.Key, &repo, &payload); err != nil {
+		log.Println(err)
 	}
 
-	url := fmt.Sprintf("/%s/%s", sess.User.Username, name)
+	url := fmt.Sprintf("/%s/%s", owner.Username, name)
 	http.Redirect(w, req, url, http.StatusSeeOther)
 }
@@ -0,0 +1,76 @@
+package repo
+
+import (
+	"errors"
+	"net/http"
+
+	cid "github.com/ipfs/go-cid"
+	pin "github.com/ipfs/go-ipfs-pinner"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/pages"
+)
+
+var errConfirm = errors.New("confirmation does not match the repository name")
+
+func (s *Repo) Delete(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	confirm := req.FormValue("confirm")
+
+	_, user, repo, ok := s.findRepoWithRole(w, req, database.RoleOwner)
+	if !ok {
+		return
+	}
+
+	if confirm != repo.Name {
+		http.Error(w, errConfirm.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := cid.Decode(repo.CID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// acquire a pinlock so pins are not changed concurrently
+	defer s.Node.Blockstore.PinLock().Unlock()
+
+	if err := pages.Unpublish(ctx, (*core.Server)(s), repo); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := (*core.Server)(s).DeleteReleases(ctx, repo); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := repo.Delete(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// other repos can share the same CID
+	count, err := database.CountReposByCID(s.DB, repo.CID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_, pinned, err := s.Node.Pinning.IsPinnedWithType(ctx, id, pin.Recursive)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if count == 0 && pinned {
+		if err := s.Node.Pinning.Unpin(ctx, id, true); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	http.Redirect(w, req, "/"+user.Username, http.StatusSeeOther)
+}
@@ -0,0 +1,132 @@
+package repo
+
+import (
+	"errors"
+	"net/http"
+	"path"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+)
+
+func (s *Repo) EditDescription(w http.ResponseWriter, req *http.Request) {
+	_, user, repo, ok := s.findRepoWithRole(w, req, database.RoleMaintain)
+	if !ok {
+		return
+	}
+
+	repo.Description = req.FormValue("description")
+	if err := repo.UpdateDescription(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	url := path.Join("/", user.Username, repo.Name, "settings")
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Repo) EditVisibility(w http.ResponseWriter, req *http.Request) {
+	_, user, repo, ok := s.findRepoWithRole(w, req, database.RoleAdmin)
+	if !ok {
+		return
+	}
+
+	repo.Visibility = req.FormValue("visibility")
+	if err := repo.UpdateVisibility(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	url := path.Join("/", user.Username, repo.Name, "settings")
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Repo) Rename(w http.ResponseWriter, req *http.Request) {
+	name := req.FormValue("name")
+
+	_, user, repo, ok := s.findRepoWithRole(w, req, database.RoleAdmin)
+	if !ok {
+		return
+	}
+
+	if err := s.move(repo, name, user); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	url := path.Join("/", user.Username, repo.Name, "settings")
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Repo) Transfer(w http.ResponseWriter, req *http.Request) {
+	username := req.FormValue("username")
+
+	sess, user, repo, ok := s.findRepoWithRole(w, req, database.RoleOwner)
+	if !ok {
+		return
+	}
+
+	var owner database.User
+	if err := owner.FindByUsername(s.DB, username); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if owner.Org && !database.IsOrgOwner(s.DB, owner.ID, sess.UserID) {
+		http.Error(w, errTransferOrg.Error(), http.StatusForbidden)
+		return
+	}
+
+	// other users must accept the transfer before they own the repo
+	if !owner.Org && owner.ID != sess.UserID {
+		transfer := database.Transfer{
+			RepoID: repo.ID,
+			UserID: owner.ID,
+		}
+
+		if err := transfer.Save(s.DB); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		url := path.Join("/", user.Username, repo.Name, "settings")
+		http.Redirect(w, req, url, http.StatusSeeOther)
+		return
+	}
+
+	if err := s.move(repo, repo.Name, &owner); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	url := path.Join("/", owner.Username, repo.Name)
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Repo) CancelTransfer(w http.ResponseWriter, req *http.Request) {
+	_, user, repo, ok := s.findRepoWithRole(w, req, database.RoleOwner)
+	if !ok {
+		return
+	}
+
+	if err := database.DeleteTransfersByRepoID(s.DB, repo.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	url := path.Join("/", user.Username, repo.Name, "settings")
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+// move changes the name and owner of the repo if the new location is free.
+func (s *Repo) move(repo *database.Repo, name string, owner *database.User) error {
+	if name == repo.Name && owner.ID == repo.UserID {
+		return errors.New("repository is already at this location")
+	}
+
+	var other database.Repo
+	if err := other.FindByNameAndUserID(s.DB, name, owner.ID); err == nil {
+		return errors.New("repository already exists")
+	}
+
+	return repo.Move(s.DB, name, owner.ID)
+}
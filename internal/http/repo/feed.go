@@ -0,0 +1,241 @@
+package repo
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/gorilla/mux"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/atom"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/gitutil"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
+)
+
+// RepoFeedEntries is the amount of entries in repository feeds.
+const RepoFeedEntries = 30
+
+// CommitsFeed writes an Atom feed of the latest commits of a branch, tag
+// or the default branch.
+func (s *Repo) CommitsFeed(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	sess, _ := session.Get(req, s.DB)
+
+	params := mux.Vars(req)
+	username := params["user"]
+	reponame := params["repo"]
+	refname := req.URL.Query().Get("ref")
+
+	var user database.User
+	if err := user.FindByUsername(s.DB, username); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var repo database.Repo
+	if err := repo.FindByNameAndUserID(s.DB, reponame, user.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !repo.CanRead(s.DB, sess.Viewer()) {
+		http.Error(w, errNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	git, err := (*core.Server)(s).OpenRepo(ctx, &repo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	head, err := gitutil.HeadOrDefault(git)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if refname != "" {
+		head, err = gitutil.Resolve(git, refname)
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	base := atom.BaseURL(req) + path.Join("/", user.Username, repo.Name)
+	self := base + "/logs.atom"
+	if refname != "" {
+		self += "?ref=" + url.QueryEscape(refname)
+	}
+
+	feed := atom.Feed{
+		ID:      self,
+		Title:   fmt.Sprintf("Commits of %s/%s", user.Username, repo.Name),
+		Updated: repo.CreatedAt,
+		Links: []atom.Link{
+			{Href: base + "/logs"},
+			{Href: self, Rel: "self"},
+		},
+	}
+
+	// empty repos have an empty feed
+	if head != nil {
+		feed.Title = fmt.Sprintf("Commits of %s/%s on %s", user.Username, repo.Name, head.Name().Short())
+
+		logs, err := gitutil.Logs(git, head, 0, RepoFeedEntries)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		for _, commit := range logs {
+			link := base + "/tree/" + commit.Hash.String()
+			title := strings.SplitN(strings.TrimSpace(commit.Message), "\n", 2)[0]
+
+			feed.Entries = append(feed.Entries, atom.Entry{
+				ID:      link,
+				Title:   title,
+				Updated: commit.Committer.When,
+				Author:  &atom.Person{Name: commit.Author.Name},
+				Links:   []atom.Link{{Href: link}},
+				Content: &atom.Text{Type: "text", Body: commit.Message},
+			})
+		}
+	}
+
+	atom.Serve(w, req, &feed, repo.Visibility == database.RepoPublic)
+}
+
+// TagsFeed writes an Atom feed of the newest tags and their releases.
+func (s *Repo) TagsFeed(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	sess, _ := session.Get(req, s.DB)
+
+	params := mux.Vars(req)
+	username := params["user"]
+	reponame := params["repo"]
+
+	var user database.User
+	if err := user.FindByUsername(s.DB, username); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var repo database.Repo
+	if err := repo.FindByNameAndUserID(s.DB, reponame, user.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !repo.CanRead(s.DB, sess.Viewer()) {
+		http.Error(w, errNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	git, err := (*core.Server)(s).OpenRepo(ctx, &repo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	tags, err := gitutil.Tags(git)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	releases, err := database.FindReleasesByRepoID(s.DB, repo.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	tagReleases := make(map[string]database.Release)
+	for _, release := range releases {
+		tagReleases[release.Tag] = release
+	}
+
+	base := atom.BaseURL(req) + path.Join("/", user.Username, repo.Name)
+	feed := atom.Feed{
+		ID:      base + "/tags.atom",
+		Title:   fmt.Sprintf("Tags of %s/%s", user.Username, repo.Name),
+		Updated: repo.CreatedAt,
+		Links: []atom.Link{
+			{Href: base + "/refs"},
+			{Href: base + "/tags.atom", Rel: "self"},
+		},
+	}
+
+	for _, tag := range tags {
+		entry, err := tagEntry(git, base, tag)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if release, ok := tagReleases[tag.Name().Short()]; ok {
+			link := fmt.Sprintf("%s/releases#release-%d", base, release.ID)
+
+			entry.Title = release.Title
+			entry.Links = append(entry.Links, atom.Link{Href: link, Rel: "related"})
+			entry.Content = &atom.Text{Type: "text", Body: release.Notes}
+		}
+
+		feed.Entries = append(feed.Entries, *entry)
+	}
+
+	sort.Slice(feed.Entries, func(i, j int) bool {
+		return feed.Entries[i].Updated.After(feed.Entries[j].Updated)
+	})
+
+	if len(feed.Entries) > RepoFeedEntries {
+		feed.Entries = feed.Entries[:RepoFeedEntries]
+	}
+
+	atom.Serve(w, req, &feed, repo.Visibility == database.RepoPublic)
+}
+
+// tagEntry returns a feed entry for the tag.
+//
+// Annotated tags are dated by their tagger and lightweight tags by their commit.
+func tagEntry(git *git.Repository, base string, ref *plumbing.Reference) (*atom.Entry, error) {
+	commit, err := gitutil.Commit(git, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var when time.Time
+	var author, message string
+
+	tag, err := git.TagObject(ref.Hash())
+	switch err {
+	case nil:
+		when, author, message = tag.Tagger.When, tag.Tagger.Name, tag.Message
+	case plumbing.ErrObjectNotFound:
+		when, author, message = commit.Committer.When, commit.Author.Name, commit.Message
+	default:
+		return nil, err
+	}
+
+	link := base + "/tree/" + ref.Name().String()
+	return &atom.Entry{
+		ID:      link,
+		Title:   ref.Name().Short(),
+		Updated: when,
+		Author:  &atom.Person{Name: author},
+		Links:   []atom.Link{{Href: link}},
+		Content: &atom.Text{Type: "text", Body: message},
+	}, nil
+}
@@ -0,0 +1,122 @@
+package repo
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"path"
+
+	"github.com/gorilla/mux"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/view"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/webhook"
+)
+
+var errForkExists = errors.New("you already have a repository with this name")
+
+func (s *Repo) Fork(w http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	username := params["user"]
+	reponame := params["repo"]
+
+	sess, err := session.Get(req, s.DB)
+	if err != nil {
+		http.Redirect(w, req, "/_log_in", http.StatusSeeOther)
+		return
+	}
+
+	var user database.User
+	if err := user.FindByUsername(s.DB, username); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var repo database.Repo
+	if err := repo.FindByNameAndUserID(s.DB, reponame, user.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !repo.CanRead(s.DB, sess.Viewer()) {
+		http.Error(w, errNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	var other database.Repo
+	if err := other.FindByNameAndUserID(s.DB, repo.Name, sess.UserID); err == nil {
+		http.Error(w, errForkExists.Error(), http.StatusBadRequest)
+		return
+	}
+
+	fork, err := (*core.Server)(s).ForkRepo(&repo, sess.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := (*core.Server)(s).RecordActivity(sess.UserID, fork, database.ActivityFork); err != nil {
+		log.Println(err)
+	}
+
+	payload := webhook.Payload{
+		Event: database.WebhookCreateEvent,
+		Repository: webhook.Repository{
+			Owner: sess.User.Username,
+			Name:  fork.Name,
+			CID:   fork.CID,
+		},
+	}
+
+	if err := webhook.Trigger(s.DB, s.Key, fork, &payload); err != nil {
+		log.Println(err)
+	}
+
+	url := path.Join("/", sess.User.Username, fork.Name)
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Repo) Forks(w http.ResponseWriter, req *http.Request) {
+	data := make(map[string]interface{})
+
+	sess, err := session.Get(req, s.DB)
+	if err == nil {
+		data["Session"] = sess
+	}
+
+	params := mux.Vars(req)
+	username := params["user"]
+	reponame := params["repo"]
+
+	var user database.User
+	if err := user.FindByUsername(s.DB, username); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var repo database.Repo
+	if err := repo.FindByNameAndUserID(s.DB, reponame, user.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !repo.CanRead(s.DB, sess.Viewer()) {
+		http.Error(w, errNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	forks, err := database.FindForksByParentID(s.DB.Scopes(database.VisibleRepos(sess.Viewer())), repo.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data["User"] = user
+	data["Repo"] = repo
+	data["Role"] = repo.Role(s.DB, sess.Viewer())
+	data["Forks"] = forks
+	data["Tab"] = RepoForksTab
+	view.Render(w, "repo.html", data)
+}
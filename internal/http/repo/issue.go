@@ -0,0 +1,469 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/view"
+)
+
+var (
+	errIssuePermission  = errors.New("you do not have permission to change this issue")
+	errTriagePermission = errors.New("you do not have permission to triage issues")
+	errAssignee         = errors.New("assignees must have access to the repository")
+)
+
+func (s *Repo) Issues(w http.ResponseWriter, req *http.Request) {
+	data := make(map[string]interface{})
+
+	sess, err := session.Get(req, s.DB)
+	if err == nil {
+		data["Session"] = sess
+	}
+
+	params := mux.Vars(req)
+	username := params["user"]
+	reponame := params["repo"]
+	state := req.URL.Query().Get("state")
+	label := req.URL.Query().Get("label")
+
+	if state == "" {
+		state = database.IssueOpen
+	}
+
+	var user database.User
+	if err := user.FindByUsername(s.DB, username); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var repo database.Repo
+	if err := repo.FindByNameAndUserID(s.DB, reponame, user.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !repo.CanRead(s.DB, sess.Viewer()) {
+		http.Error(w, errNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	issues, err := database.FindIssuesByRepoID(s.DB, repo.ID, state, label)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	labels, err := database.FindLabelsByRepoID(s.DB, repo.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data["User"] = user
+	data["Repo"] = repo
+	data["Role"] = repo.Role(s.DB, sess.Viewer())
+	data["Issues"] = issues
+	data["Labels"] = labels
+	data["State"] = state
+	data["Label"] = label
+	data["Tab"] = RepoIssuesTab
+	view.Render(w, "repo.html", data)
+}
+
+func (s *Repo) NewIssue(w http.ResponseWriter, req *http.Request) {
+	data := make(map[string]interface{})
+
+	sess, user, repo, ok := s.findRepoWithRole(w, req, "")
+	if !ok {
+		return
+	}
+
+	data["Session"] = sess
+	data["User"] = user
+	data["Repo"] = repo
+	data["Role"] = repo.Role(s.DB, sess.Viewer())
+	data["NewIssue"] = true
+	data["Tab"] = RepoIssuesTab
+	view.Render(w, "repo.html", data)
+}
+
+func (s *Repo) CreateIssue(w http.ResponseWriter, req *http.Request) {
+	sess, user, repo, ok := s.findRepoWithRole(w, req, "")
+	if !ok {
+		return
+	}
+
+	issue := database.Issue{
+		RepoID: repo.ID,
+		UserID: sess.UserID,
+		Title:  req.FormValue("title"),
+		Body:   req.FormValue("body"),
+	}
+
+	if err := issue.Create(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.publishDiscussion(repo.ID)
+
+	url := path.Join("/", user.Username, repo.Name, "issues", fmt.Sprint(issue.Number))
+	title := fmt.Sprintf("%s opened issue #%d: %s", sess.User.Username, issue.Number, issue.Title)
+	s.notify(sess, user, repo, database.NotificationIssue, title, url, issue.Body)
+
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Repo) Issue(w http.ResponseWriter, req *http.Request) {
+	data := make(map[string]interface{})
+
+	sess, err := session.Get(req, s.DB)
+	if err == nil {
+		data["Session"] = sess
+	}
+
+	params := mux.Vars(req)
+	username := params["user"]
+	reponame := params["repo"]
+
+	var user database.User
+	if err := user.FindByUsername(s.DB, username); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var repo database.Repo
+	if err := repo.FindByNameAndUserID(s.DB, reponame, user.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !repo.CanRead(s.DB, sess.Viewer()) {
+		http.Error(w, errNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	var issue database.Issue
+	if err := issue.FindByNumberAndRepoID(s.DB, params["number"], repo.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	comments, err := database.FindIssueCommentsByIssueID(s.DB, issue.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	labels, err := database.FindLabelsByRepoID(s.DB, repo.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	issueLabels := make(map[uint]bool)
+	for _, l := range issue.Labels {
+		issueLabels[l.ID] = true
+	}
+
+	role := repo.Role(s.DB, sess.Viewer())
+
+	data["User"] = user
+	data["Repo"] = repo
+	data["Role"] = role
+	data["Issue"] = issue
+	data["Comments"] = comments
+	data["Labels"] = labels
+	data["IssueLabels"] = issueLabels
+	data["CanEdit"] = sess != nil && (sess.UserID == issue.UserID || database.HasRole(role, database.RoleWrite))
+	data["Tab"] = RepoIssuesTab
+	view.Render(w, "repo.html", data)
+}
+
+func (s *Repo) CommentIssue(w http.ResponseWriter, req *http.Request) {
+	sess, user, repo, issue, ok := s.findIssue(w, req)
+	if !ok {
+		return
+	}
+
+	comment := database.IssueComment{
+		IssueID: issue.ID,
+		UserID:  sess.UserID,
+		Body:    req.FormValue("body"),
+	}
+
+	if err := comment.Save(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.publishDiscussion(repo.ID)
+
+	url := path.Join("/", user.Username, repo.Name, "issues", fmt.Sprint(issue.Number))
+	title := fmt.Sprintf("%s commented on issue #%d: %s", sess.User.Username, issue.Number, issue.Title)
+	s.notify(sess, user, repo, database.NotificationComment, title, url, comment.Body)
+
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Repo) CloseIssue(w http.ResponseWriter, req *http.Request) {
+	s.setIssueState(w, req, database.IssueClosed)
+}
+
+func (s *Repo) ReopenIssue(w http.ResponseWriter, req *http.Request) {
+	s.setIssueState(w, req, database.IssueOpen)
+}
+
+func (s *Repo) EditIssueLabels(w http.ResponseWriter, req *http.Request) {
+	_, user, repo, issue, ok := s.findTriageIssue(w, req)
+	if !ok {
+		return
+	}
+
+	if err := req.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	labels, err := database.FindLabelsByIDs(s.DB, repo.ID, req.PostForm["label"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := issue.SetLabels(s.DB, labels); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.publishDiscussion(repo.ID)
+
+	url := path.Join("/", user.Username, repo.Name, "issues", fmt.Sprint(issue.Number))
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Repo) AddAssignee(w http.ResponseWriter, req *http.Request) {
+	username := req.FormValue("username")
+
+	_, user, repo, issue, ok := s.findTriageIssue(w, req)
+	if !ok {
+		return
+	}
+
+	var assignee database.User
+	if err := assignee.FindByUsername(s.DB, username); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if assignee.Org || !repo.HasRole(s.DB, &assignee, database.RoleRead) {
+		http.Error(w, errAssignee.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := issue.AddAssignee(s.DB, &assignee); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.publishDiscussion(repo.ID)
+
+	url := path.Join("/", user.Username, repo.Name, "issues", fmt.Sprint(issue.Number))
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Repo) RemoveAssignee(w http.ResponseWriter, req *http.Request) {
+	_, user, repo, issue, ok := s.findTriageIssue(w, req)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(mux.Vars(req)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var assignee database.User
+	if err := assignee.Find(s.DB, id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := issue.RemoveAssignee(s.DB, &assignee); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.publishDiscussion(repo.ID)
+
+	url := path.Join("/", user.Username, repo.Name, "issues", fmt.Sprint(issue.Number))
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Repo) CreateLabel(w http.ResponseWriter, req *http.Request) {
+	sess, user, repo, ok := s.findRepoWithRole(w, req, "")
+	if !ok {
+		return
+	}
+
+	if !repo.HasRole(s.DB, sess.Viewer(), database.RoleWrite) {
+		http.Error(w, errTriagePermission.Error(), http.StatusForbidden)
+		return
+	}
+
+	label := database.Label{
+		RepoID: repo.ID,
+		Name:   req.FormValue("name"),
+	}
+
+	if err := label.Save(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.publishDiscussion(repo.ID)
+
+	url := path.Join("/", user.Username, repo.Name, "issues")
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Repo) DeleteLabel(w http.ResponseWriter, req *http.Request) {
+	sess, user, repo, ok := s.findRepoWithRole(w, req, "")
+	if !ok {
+		return
+	}
+
+	if !repo.HasRole(s.DB, sess.Viewer(), database.RoleWrite) {
+		http.Error(w, errTriagePermission.Error(), http.StatusForbidden)
+		return
+	}
+
+	var label database.Label
+	if err := label.FindByIDAndRepoID(s.DB, mux.Vars(req)["id"], repo.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := label.Delete(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.publishDiscussion(repo.ID)
+
+	url := path.Join("/", user.Username, repo.Name, "issues")
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+// setIssueState changes the state of the issue.
+//
+// Only the author and users with the write role can change the state.
+func (s *Repo) setIssueState(w http.ResponseWriter, req *http.Request, state string) {
+	sess, user, repo, issue, ok := s.findIssue(w, req)
+	if !ok {
+		return
+	}
+
+	if sess.UserID != issue.UserID && !repo.HasRole(s.DB, sess.Viewer(), database.RoleWrite) {
+		http.Error(w, errIssuePermission.Error(), http.StatusForbidden)
+		return
+	}
+
+	issue.State = state
+	if err := issue.Save(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.publishDiscussion(repo.ID)
+
+	url := path.Join("/", user.Username, repo.Name, "issues", fmt.Sprint(issue.Number))
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+// findIssue returns the session, owner, repo and issue of the request.
+//
+// An error response is written if the session user cannot read the repo.
+func (s *Repo) findIssue(w http.ResponseWriter, req *http.Request) (*database.Session, *database.User, *database.Repo, *database.Issue, bool) {
+	sess, user, repo, ok := s.findRepoWithRole(w, req, "")
+	if !ok {
+		return nil, nil, nil, nil, false
+	}
+
+	var issue database.Issue
+	if err := issue.FindByNumberAndRepoID(s.DB, mux.Vars(req)["number"], repo.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return nil, nil, nil, nil, false
+	}
+
+	return sess, user, repo, &issue, true
+}
+
+// findTriageIssue is like findIssue but also requires the write role.
+func (s *Repo) findTriageIssue(w http.ResponseWriter, req *http.Request) (*database.Session, *database.User, *database.Repo, *database.Issue, bool) {
+	sess, user, repo, issue, ok := s.findIssue(w, req)
+	if !ok {
+		return nil, nil, nil, nil, false
+	}
+
+	if !repo.HasRole(s.DB, sess.Viewer(), database.RoleWrite) {
+		http.Error(w, errTriagePermission.Error(), http.StatusForbidden)
+		return nil, nil, nil, nil, false
+	}
+
+	return sess, user, repo, issue, true
+}
+
+// publishDiscussion writes the discussion of the repo to its content.
+//
+// Errors are only logged because the database is updated already
+// and the next publish of the repo includes all discussions.
+func (s *Repo) publishDiscussion(repoID uint) {
+	// the repo must be loaded after locking so its CID is current
+	defer s.Node.Blockstore.PinLock().Unlock()
+
+	unlock := (*core.Server)(s).LockRepo(repoID)
+	defer unlock()
+
+	var repo database.Repo
+	if err := repo.Find(s.DB, repoID); err != nil {
+		log.Println(err)
+		return
+	}
+
+	if err := (*core.Server)(s).PublishDiscussion(context.Background(), &repo); err != nil {
+		log.Println(err)
+	}
+}
+
+// notify notifies watchers and mentioned users of an event on the repo
+// owned by the user. Errors are only logged.
+func (s *Repo) notify(sess *database.Session, user *database.User, repo *database.Repo, typ, title, link, text string) {
+	owned := *repo
+	owned.User = *user
+
+	notice := core.Notice{
+		Actor: &sess.User,
+		Repo:  &owned,
+		Type:  typ,
+		Title: title,
+		Link:  link,
+		Text:  text,
+	}
+
+	if err := (*core.Server)(s).Notify(&notice); err != nil {
+		log.Println(err)
+	}
+}
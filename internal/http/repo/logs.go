@@ -6,6 +6,7 @@ import (
 
 	"github.com/gorilla/mux"
 
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
 	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
 	"github.com/multiverse-vcs/go-git-ipfs/internal/gitutil"
 	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
@@ -24,6 +25,7 @@ func (s *Repo) Logs(w http.ResponseWriter, req *http.Request) {
 	params := mux.Vars(req)
 	username := params["user"]
 	reponame := params["repo"]
+	refname := req.URL.Query().Get("ref")
 	offset := req.URL.Query().Get("offset")
 
 	if offset == "" {
@@ -48,11 +50,17 @@ func (s *Repo) Logs(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if !repo.CanRead(s.DB, sess.Viewer()) {
+		http.Error(w, errNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
 	data["User"] = user
 	data["Repo"] = repo
+	data["Role"] = repo.Role(s.DB, sess.Viewer())
 	data["Tab"] = RepoLogsTab
 
-	git, err := gitutil.Open(ctx, s.Node.DAG, repo.CID)
+	git, err := (*core.Server)(s).OpenRepo(ctx, &repo)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -69,12 +77,36 @@ func (s *Repo) Logs(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if refname != "" {
+		head, err = gitutil.Resolve(git, refname)
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), gitStatus(err))
+		return
+	}
+
+	branches, err := gitutil.Branches(git)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	tags, err := gitutil.Tags(git)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	logs, err := gitutil.Logs(git, head, int(offsetnum), RepoLogsPerPage)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	data["Ref"] = head.Name().String()
+	data["Tags"] = tags
+	data["Branches"] = branches
 	data["Commits"] = logs
 	data["Next"] = offsetnum + RepoLogsPerPage
 	data["Prev"] = offsetnum - RepoLogsPerPage
@@ -0,0 +1,150 @@
+package repo
+
+import (
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/gorilla/mux"
+	cid "github.com/ipfs/go-cid"
+	ipld "github.com/ipfs/go-ipld-format"
+	ufs "github.com/ipfs/go-unixfs"
+	ufsio "github.com/ipfs/go-unixfs/io"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
+)
+
+// PagesIndex is the file served for directory requests.
+const PagesIndex = "index.html"
+
+// Pages serves files from the materialized tree of a branch or tag.
+func (s *Repo) Pages(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	sess, _ := session.Get(req, s.DB)
+
+	params := mux.Vars(req)
+	username := params["user"]
+	reponame := params["repo"]
+	refpath := params["refpath"]
+
+	var user database.User
+	if err := user.FindByUsername(s.DB, username); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var repo database.Repo
+	if err := repo.FindByNameAndUserID(s.DB, reponame, user.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !repo.CanRead(s.DB, sess.Viewer()) {
+		http.Error(w, errNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	if !repo.Pages {
+		http.NotFound(w, req)
+		return
+	}
+
+	var snapshots []database.Snapshot
+	if err := s.DB.Find(&snapshots, "repo_id = ?", repo.ID).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// refs can be referenced by their full or short names
+	var snap *database.Snapshot
+	var fpath string
+	for i, sn := range snapshots {
+		name := plumbing.ReferenceName(sn.Name)
+		for _, prefix := range []string{name.String(), name.Short()} {
+			if refpath != prefix && !strings.HasPrefix(refpath, prefix+"/") {
+				continue
+			}
+
+			if snap == nil || len(snap.Name) < len(sn.Name) {
+				snap = &snapshots[i]
+				fpath = strings.TrimPrefix(refpath, prefix)
+			}
+		}
+	}
+
+	if snap == nil {
+		http.NotFound(w, req)
+		return
+	}
+
+	id, err := cid.Decode(snap.CID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	node, err := s.Node.DAG.Get(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for _, name := range strings.Split(strings.Trim(fpath, "/"), "/") {
+		if name == "" {
+			continue
+		}
+
+		node, err = s.findChild(req, node, name)
+		if err != nil {
+			http.NotFound(w, req)
+			return
+		}
+	}
+
+	fsnode, err := ufs.ExtractFSNode(node)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if fsnode.IsDir() {
+		if !strings.HasSuffix(req.URL.Path, "/") {
+			http.Redirect(w, req, req.URL.Path+"/", http.StatusMovedPermanently)
+			return
+		}
+
+		if node, err = s.findChild(req, node, PagesIndex); err != nil {
+			http.NotFound(w, req)
+			return
+		}
+
+		fpath = path.Join(fpath, PagesIndex)
+	}
+
+	r, err := ufsio.NewDagReader(ctx, node, s.Node.DAG)
+	if err != nil {
+		http.NotFound(w, req)
+		return
+	}
+	defer r.Close()
+
+	// pages share an origin with the rest of the site so
+	// scripts must not be able to act on behalf of the viewer
+	w.Header().Set("Content-Security-Policy", "sandbox allow-scripts allow-forms allow-popups")
+	w.Header().Set("X-Ipfs-Path", "/ipfs/"+snap.CID+path.Clean("/"+fpath))
+	w.Header().Set("Etag", `"`+node.Cid().String()+`"`)
+	http.ServeContent(w, req, path.Base(fpath), snap.UpdatedAt, r)
+}
+
+// findChild returns the child with the given name from the directory node.
+func (s *Repo) findChild(req *http.Request, node ipld.Node, name string) (ipld.Node, error) {
+	dir, err := ufsio.NewDirectoryFromNode(s.Node.DAG, node)
+	if err != nil {
+		return nil, err
+	}
+
+	return dir.Find(req.Context(), name)
+}
@@ -0,0 +1,642 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/gorilla/mux"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/codesearch"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/gitutil"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/pages"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/view"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/webhook"
+)
+
+var (
+	errHeadRepo        = errors.New("head repository must be this repository or one of its forks")
+	errPullClosed      = errors.New("pull request is not open")
+	errPullPermission  = errors.New("you do not have permission to change this pull request")
+	errMergePermission = errors.New("you do not have permission to merge pull requests")
+	errOwnReview       = errors.New("you cannot review your own pull request")
+)
+
+// comparison contains the changes of a pull request.
+type comparison struct {
+	MergeBase plumbing.Hash
+	Head      plumbing.Hash
+	Commits   []*object.Commit
+	Diffs     []gitutil.FileDiff
+}
+
+func (s *Repo) Pulls(w http.ResponseWriter, req *http.Request) {
+	data := make(map[string]interface{})
+
+	sess, err := session.Get(req, s.DB)
+	if err == nil {
+		data["Session"] = sess
+	}
+
+	params := mux.Vars(req)
+	username := params["user"]
+	reponame := params["repo"]
+	state := req.URL.Query().Get("state")
+
+	if state == "" {
+		state = database.PullOpen
+	}
+
+	var user database.User
+	if err := user.FindByUsername(s.DB, username); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var repo database.Repo
+	if err := repo.FindByNameAndUserID(s.DB, reponame, user.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !repo.CanRead(s.DB, sess.Viewer()) {
+		http.Error(w, errNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	pulls, err := database.FindPullRequestsByRepoID(s.DB, repo.ID, state)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data["User"] = user
+	data["Repo"] = repo
+	data["Role"] = repo.Role(s.DB, sess.Viewer())
+	data["Pulls"] = pulls
+	data["State"] = state
+	data["Tab"] = RepoPullsTab
+	view.Render(w, "repo.html", data)
+}
+
+func (s *Repo) NewPull(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	data := make(map[string]interface{})
+
+	sess, user, repo, ok := s.findRepoWithRole(w, req, "")
+	if !ok {
+		return
+	}
+
+	git, err := (*core.Server)(s).OpenRepo(ctx, repo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	branches, err := gitutil.Branches(git)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	forks, err := database.FindForksByParentID(s.DB.Scopes(database.VisibleRepos(sess.Viewer())), repo.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	self := *repo
+	self.User = *user
+
+	data["Session"] = sess
+	data["User"] = user
+	data["Repo"] = repo
+	data["Role"] = repo.Role(s.DB, sess.Viewer())
+	data["Branches"] = branches
+	data["Heads"] = append([]database.Repo{self}, forks...)
+	data["Tab"] = RepoPullsTab
+	view.Render(w, "repo.html", data)
+}
+
+func (s *Repo) CreatePull(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	sess, user, repo, ok := s.findRepoWithRole(w, req, "")
+	if !ok {
+		return
+	}
+
+	headID, err := strconv.ParseUint(req.FormValue("head_repo"), 10, 64)
+	if err != nil {
+		http.Error(w, errHeadRepo.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var head database.Repo
+	if err := head.Find(s.DB, headID); err != nil {
+		http.Error(w, errHeadRepo.Error(), http.StatusBadRequest)
+		return
+	}
+
+	isFork := head.ParentID != nil && *head.ParentID == repo.ID
+	if (head.ID != repo.ID && !isFork) || !head.CanRead(s.DB, sess.Viewer()) {
+		http.Error(w, errHeadRepo.Error(), http.StatusBadRequest)
+		return
+	}
+
+	pull := database.PullRequest{
+		RepoID:     repo.ID,
+		UserID:     sess.UserID,
+		Title:      req.FormValue("title"),
+		Body:       req.FormValue("body"),
+		BaseRef:    req.FormValue("base"),
+		HeadRepoID: head.ID,
+		HeadRef:    req.FormValue("head"),
+	}
+
+	pull.HeadRepo = head
+	if _, err := s.compare(ctx, repo, &pull); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := pull.Create(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.publishDiscussion(repo.ID)
+
+	url := path.Join("/", user.Username, repo.Name, "pulls", fmt.Sprint(pull.Number))
+	title := fmt.Sprintf("%s opened pull request #%d: %s", sess.User.Username, pull.Number, pull.Title)
+	s.notify(sess, user, repo, database.NotificationPull, title, url, pull.Body)
+
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Repo) Pull(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	data := make(map[string]interface{})
+
+	sess, err := session.Get(req, s.DB)
+	if err == nil {
+		data["Session"] = sess
+	}
+
+	params := mux.Vars(req)
+	username := params["user"]
+	reponame := params["repo"]
+	query := req.URL.Query()
+
+	var user database.User
+	if err := user.FindByUsername(s.DB, username); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var repo database.Repo
+	if err := repo.FindByNameAndUserID(s.DB, reponame, user.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !repo.CanRead(s.DB, sess.Viewer()) {
+		http.Error(w, errNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	var pull database.PullRequest
+	if err := pull.FindByNumberAndRepoID(s.DB, params["number"], repo.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	comments, err := database.FindPullCommentsByPullRequestID(s.DB, pull.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	reviews, err := database.FindPullReviewsByPullRequestID(s.DB, pull.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var conversation []database.PullComment
+	lineComments := make(map[string]map[int][]database.PullComment)
+	for _, c := range comments {
+		if c.Path == "" {
+			conversation = append(conversation, c)
+			continue
+		}
+
+		if lineComments[c.Path] == nil {
+			lineComments[c.Path] = make(map[int][]database.PullComment)
+		}
+
+		lineComments[c.Path][c.Line] = append(lineComments[c.Path][c.Line], c)
+	}
+
+	// a missing head branch is shown on the page instead of failing
+	cmp, err := s.compare(ctx, &repo, &pull)
+	if err != nil {
+		data["CompareError"] = err.Error()
+	} else {
+		data["Commits"] = cmp.Commits
+		data["Diffs"] = cmp.Diffs
+	}
+
+	role := repo.Role(s.DB, sess.Viewer())
+
+	data["User"] = user
+	data["Repo"] = repo
+	data["Role"] = role
+	data["Pull"] = pull
+	data["Comments"] = conversation
+	data["LineComments"] = lineComments
+	data["Reviews"] = reviews
+	data["CommentPath"] = query.Get("path")
+	data["CommentLine"] = query.Get("line")
+	data["ReviewStates"] = database.ReviewStates
+	data["MergeMethods"] = gitutil.MergeMethods
+	data["CanEdit"] = sess != nil && (sess.UserID == pull.UserID || database.HasRole(role, database.RoleWrite))
+	data["Tab"] = RepoPullsTab
+	view.Render(w, "repo.html", data)
+}
+
+func (s *Repo) CommentPull(w http.ResponseWriter, req *http.Request) {
+	sess, user, repo, pull, ok := s.findPull(w, req)
+	if !ok {
+		return
+	}
+
+	comment := database.PullComment{
+		PullRequestID: pull.ID,
+		UserID:        sess.UserID,
+		Body:          req.FormValue("body"),
+		Path:          req.FormValue("path"),
+	}
+
+	if line := req.FormValue("line"); line != "" {
+		num, err := strconv.Atoi(line)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		comment.Line = num
+	}
+
+	if err := comment.Save(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.publishDiscussion(repo.ID)
+
+	url := path.Join("/", user.Username, repo.Name, "pulls", fmt.Sprint(pull.Number))
+	title := fmt.Sprintf("%s commented on pull request #%d: %s", sess.User.Username, pull.Number, pull.Title)
+	s.notify(sess, user, repo, database.NotificationComment, title, url, comment.Body)
+
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Repo) ReviewPull(w http.ResponseWriter, req *http.Request) {
+	sess, user, repo, pull, ok := s.findPull(w, req)
+	if !ok {
+		return
+	}
+
+	if sess.UserID == pull.UserID {
+		http.Error(w, errOwnReview.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if pull.State != database.PullOpen {
+		http.Error(w, errPullClosed.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review := database.PullReview{
+		PullRequestID: pull.ID,
+		UserID:        sess.UserID,
+		State:         req.FormValue("state"),
+		Body:          req.FormValue("body"),
+	}
+
+	if err := review.Save(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.publishDiscussion(repo.ID)
+
+	url := path.Join("/", user.Username, repo.Name, "pulls", fmt.Sprint(pull.Number))
+	title := fmt.Sprintf("%s reviewed pull request #%d: %s", sess.User.Username, pull.Number, pull.Title)
+	s.notify(sess, user, repo, database.NotificationComment, title, url, review.Body)
+
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Repo) ClosePull(w http.ResponseWriter, req *http.Request) {
+	s.setPullState(w, req, database.PullOpen, database.PullClosed)
+}
+
+func (s *Repo) ReopenPull(w http.ResponseWriter, req *http.Request) {
+	s.setPullState(w, req, database.PullClosed, database.PullOpen)
+}
+
+func (s *Repo) MergePull(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	method := req.FormValue("method")
+
+	// acquire a pinlock so GC doesn't wipe out changes
+	defer s.Node.Blockstore.PinLock().Unlock()
+
+	sess, user, repo, pull, ok := s.findPull(w, req)
+	if !ok {
+		return
+	}
+
+	if !repo.HasRole(s.DB, sess.Viewer(), database.RoleWrite) {
+		http.Error(w, errMergePermission.Error(), http.StatusForbidden)
+		return
+	}
+
+	server := (*core.Server)(s)
+
+	// the repo and pull request are loaded again after locking
+	// so pushes and other merges cannot interleave
+	unlock := server.LockRepo(repo.ID)
+	defer unlock()
+
+	if err := repo.Find(s.DB, repo.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := pull.FindByNumberAndRepoID(s.DB, pull.Number, repo.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if pull.State != database.PullOpen {
+		http.Error(w, errPullClosed.Error(), http.StatusBadRequest)
+		return
+	}
+
+	head, err := server.OpenRepo(ctx, &pull.HeadRepo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	headRef, err := head.Reference(plumbing.NewBranchReferenceName(pull.HeadRef), true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sig := object.Signature{
+		Name:  sess.User.Username,
+		Email: sess.User.Email,
+		When:  time.Now(),
+	}
+
+	var message string
+	switch method {
+	case gitutil.MergeSquash:
+		message = fmt.Sprintf("%s (#%d)\n\n%s", pull.Title, pull.Number, pull.Body)
+	default:
+		message = fmt.Sprintf("Merge pull request #%d from %s/%s:%s\n\n%s",
+			pull.Number, pull.HeadRepo.User.Username, pull.HeadRepo.Name, pull.HeadRef, pull.Title)
+	}
+
+	var update webhook.Ref
+	err = server.UpdateRepo(ctx, repo, func(git *git.Repository) error {
+		baseRef, err := git.Reference(plumbing.NewBranchReferenceName(pull.BaseRef), true)
+		if err != nil {
+			return err
+		}
+
+		mergeBase, err := gitutil.MergeBase(git, baseRef.Hash(), head, headRef.Hash())
+		if err != nil {
+			return err
+		}
+
+		if pull.HeadRepoID != repo.ID {
+			if err := gitutil.CopyObjects(git.Storer, head, headRef.Hash(), []plumbing.Hash{mergeBase}); err != nil {
+				return err
+			}
+		}
+
+		base, err := git.CommitObject(baseRef.Hash())
+		if err != nil {
+			return err
+		}
+
+		commit, err := git.CommitObject(headRef.Hash())
+		if err != nil {
+			return err
+		}
+
+		hash, err := gitutil.Merge(git, method, base, commit, sig, message)
+		if err != nil {
+			return err
+		}
+
+		update = webhook.Ref{
+			Name: baseRef.Name().String(),
+			Old:  baseRef.Hash().String(),
+			New:  hash.String(),
+		}
+
+		pull.MergeBase = mergeBase.String()
+		pull.HeadHash = headRef.Hash().String()
+		return git.Storer.SetReference(plumbing.NewHashReference(baseRef.Name(), hash))
+	})
+
+	switch {
+	case errors.Is(err, gitutil.ErrConflict), errors.Is(err, gitutil.ErrNotFastForward), errors.Is(err, gitutil.ErrUnrelated):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	pull.State = database.PullMerged
+	if err := pull.Save(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	name := plumbing.ReferenceName(update.Name)
+	if err := server.RecordPush(ctx, repo, sess.UserID, name, plumbing.NewHash(update.Old), plumbing.NewHash(update.New)); err != nil {
+		log.Println(err)
+	}
+
+	if err := server.CloseIssues(ctx, repo, name, plumbing.NewHash(update.Old), plumbing.NewHash(update.New)); err != nil {
+		log.Println(err)
+	}
+
+	if err := server.PublishDiscussion(ctx, repo); err != nil {
+		log.Println(err)
+	}
+
+	// index in the background like pushes do
+	go func(repo database.Repo) {
+		if err := codesearch.Index(context.Background(), s.DB, s.Node.DAG, &repo); err != nil {
+			log.Println(err)
+		}
+	}(*repo)
+
+	if repo.Pages {
+		if err := pages.Publish(ctx, server, repo); err != nil {
+			log.Println(err)
+		}
+	}
+
+	payload := webhook.Payload{
+		Event: database.WebhookPushEvent,
+		Repository: webhook.Repository{
+			Owner: user.Username,
+			Name:  repo.Name,
+			CID:   repo.CID,
+		},
+		Pusher: sess.User.Username,
+		Refs:   []webhook.Ref{update},
+	}
+
+	if err := webhook.Trigger(s.DB, s.Key, repo, &payload); err != nil {
+		log.Println(err)
+	}
+
+	url := path.Join("/", user.Username, repo.Name, "pulls", fmt.Sprint(pull.Number))
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+// setPullState changes the state of the pull request from one state to another.
+//
+// Only the author and users with the write role can change the state.
+func (s *Repo) setPullState(w http.ResponseWriter, req *http.Request, from, to string) {
+	sess, user, repo, pull, ok := s.findPull(w, req)
+	if !ok {
+		return
+	}
+
+	if sess.UserID != pull.UserID && !repo.HasRole(s.DB, sess.Viewer(), database.RoleWrite) {
+		http.Error(w, errPullPermission.Error(), http.StatusForbidden)
+		return
+	}
+
+	if pull.State != from {
+		http.Error(w, errPullClosed.Error(), http.StatusBadRequest)
+		return
+	}
+
+	pull.State = to
+	if err := pull.Save(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.publishDiscussion(repo.ID)
+
+	url := path.Join("/", user.Username, repo.Name, "pulls", fmt.Sprint(pull.Number))
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+// findPull returns the session, owner, repo and pull request of the request.
+//
+// An error response is written if the session user cannot read the repo.
+func (s *Repo) findPull(w http.ResponseWriter, req *http.Request) (*database.Session, *database.User, *database.Repo, *database.PullRequest, bool) {
+	sess, user, repo, ok := s.findRepoWithRole(w, req, "")
+	if !ok {
+		return nil, nil, nil, nil, false
+	}
+
+	var pull database.PullRequest
+	if err := pull.FindByNumberAndRepoID(s.DB, mux.Vars(req)["number"], repo.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return nil, nil, nil, nil, false
+	}
+
+	return sess, user, repo, &pull, true
+}
+
+// compare returns the commits and diff of the pull request.
+//
+// Merged pull requests are compared using the recorded commits
+// because the head branch is part of the base branch afterwards.
+func (s *Repo) compare(ctx context.Context, repo *database.Repo, pull *database.PullRequest) (*comparison, error) {
+	server := (*core.Server)(s)
+
+	base, err := server.OpenRepo(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	if pull.State == database.PullMerged {
+		return newComparison(base, plumbing.NewHash(pull.MergeBase), plumbing.NewHash(pull.HeadHash))
+	}
+
+	if pull.HeadRepo.ID == 0 {
+		return nil, errors.New("head repository no longer exists")
+	}
+
+	baseRef, err := base.Reference(plumbing.NewBranchReferenceName(pull.BaseRef), true)
+	if err != nil {
+		return nil, fmt.Errorf("base branch %s: %w", pull.BaseRef, err)
+	}
+
+	head, err := server.OpenRepo(ctx, &pull.HeadRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	headRef, err := head.Reference(plumbing.NewBranchReferenceName(pull.HeadRef), true)
+	if err != nil {
+		return nil, fmt.Errorf("head branch %s: %w", pull.HeadRef, err)
+	}
+
+	mergeBase, err := gitutil.MergeBase(base, baseRef.Hash(), head, headRef.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	// the merge base and head are both in the head repo
+	return newComparison(head, mergeBase, headRef.Hash())
+}
+
+func newComparison(git *git.Repository, mergeBase, head plumbing.Hash) (*comparison, error) {
+	commits, err := gitutil.Compare(git, mergeBase, head)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs, err := gitutil.Diff(git.Storer, mergeBase, head)
+	if err != nil {
+		return nil, err
+	}
+
+	return &comparison{
+		MergeBase: mergeBase,
+		Head:      head,
+		Commits:   commits,
+		Diffs:     diffs,
+	}, nil
+}
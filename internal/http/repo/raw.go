@@ -0,0 +1,129 @@
+package repo
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/gorilla/mux"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/gitutil"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
+)
+
+// Raw writes the contents of a blob at the given ref and path.
+func (s *Repo) Raw(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	sess, _ := session.Get(req, s.DB)
+
+	params := mux.Vars(req)
+	username := params["user"]
+	reponame := params["repo"]
+	refpath := params["refpath"]
+
+	var user database.User
+	if err := user.FindByUsername(s.DB, username); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var repo database.Repo
+	if err := repo.FindByNameAndUserID(s.DB, reponame, user.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !repo.CanRead(s.DB, sess.Viewer()) {
+		http.Error(w, errNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	git, err := (*core.Server)(s).OpenRepo(ctx, &repo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ref, subpath, err := gitutil.RefPath(git, refpath)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	obj, err := gitutil.Find(git, ref, subpath)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	blob, ok := obj.(*object.Blob)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	r, err := blob.Reader()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer r.Close()
+
+	br := bufio.NewReader(r)
+	head, err := br.Peek(512)
+	if err != nil && err != io.EOF {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ctype := rawContentType(subpath, head)
+
+	// raw files share an origin with the rest of the site
+	// so they are never allowed to run scripts, with the
+	// exception of pdfs which browsers refuse to sandbox
+	csp := "default-src 'none'; style-src 'unsafe-inline'; sandbox"
+	if ctype == "application/pdf" {
+		csp = "default-src 'none'; style-src 'unsafe-inline'"
+	}
+
+	w.Header().Set("Content-Type", ctype)
+	w.Header().Set("Content-Length", strconv.FormatInt(blob.Size, 10))
+	w.Header().Set("Content-Security-Policy", csp)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("X-Frame-Options", "SAMEORIGIN")
+	w.Header().Set("Etag", `"`+blob.Hash.String()+`"`)
+
+	io.Copy(w, br)
+}
+
+// rawContentType returns the content type used to serve a raw file.
+//
+// Media types are detected by extension and everything
+// else is served as either plain text or binary data.
+// Files named like pdfs must also contain a pdf, because
+// pdfs are served without a sandbox.
+func rawContentType(name string, head []byte) string {
+	ctype := mime.TypeByExtension(path.Ext(name))
+	if ctype == "application/pdf" && http.DetectContentType(head) != ctype {
+		ctype = ""
+	}
+	for _, prefix := range []string{"image/", "audio/", "video/", "application/pdf"} {
+		if strings.HasPrefix(ctype, prefix) {
+			return ctype
+		}
+	}
+
+	if strings.HasPrefix(http.DetectContentType(head), "text/") {
+		return "text/plain; charset=utf-8"
+	}
+
+	return "application/octet-stream"
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gorilla/mux"
 
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
 	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
 	"github.com/multiverse-vcs/go-git-ipfs/internal/gitutil"
 	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
@@ -23,6 +24,7 @@ func (s *Repo) Read(w http.ResponseWriter, req *http.Request) {
 	params := mux.Vars(req)
 	username := params["user"]
 	reponame := params["repo"]
+	refname := req.URL.Query().Get("ref")
 
 	var user database.User
 	if err := user.FindByUsername(s.DB, username); err != nil {
@@ -36,10 +38,24 @@ func (s *Repo) Read(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if !repo.CanRead(s.DB, sess.Viewer()) {
+		http.Error(w, errNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
 	data["User"] = user
 	data["Repo"] = repo
+	data["Role"] = repo.Role(s.DB, sess.Viewer())
+
+	star, err := s.findStarState(sess, &repo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data["Star"] = star
 
-	git, err := gitutil.Open(ctx, s.Node.DAG, repo.CID)
+	git, err := (*core.Server)(s).OpenRepo(ctx, &repo)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -56,13 +72,50 @@ func (s *Repo) Read(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	readme, err := gitutil.Readme(git, head)
+	if refname != "" {
+		head, err = gitutil.Resolve(git, refname)
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), gitStatus(err))
+		return
+	}
+
+	branches, err := gitutil.Branches(git)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	tags, err := gitutil.Tags(git)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	commit, err := gitutil.Commit(git, head)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	readme, err := gitutil.Readme(tree)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	data["Tab"] = RepoInfoTab
+	data["Ref"] = head.Name().String()
+	data["Path"] = ""
+	data["Tags"] = tags
+	data["Branches"] = branches
 	data["Readme"] = readme
 	view.Render(w, "repo.html", data)
 }
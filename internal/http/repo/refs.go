@@ -5,6 +5,7 @@ import (
 
 	"github.com/gorilla/mux"
 
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
 	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
 	"github.com/multiverse-vcs/go-git-ipfs/internal/gitutil"
 	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
@@ -23,6 +24,7 @@ func (s *Repo) Refs(w http.ResponseWriter, req *http.Request) {
 	params := mux.Vars(req)
 	username := params["user"]
 	reponame := params["repo"]
+	refname := req.URL.Query().Get("ref")
 
 	var user database.User
 	if err := user.FindByUsername(s.DB, username); err != nil {
@@ -36,7 +38,12 @@ func (s *Repo) Refs(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	git, err := gitutil.Open(ctx, s.Node.DAG, repo.CID)
+	if !repo.CanRead(s.DB, sess.Viewer()) {
+		http.Error(w, errNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	git, err := (*core.Server)(s).OpenRepo(ctx, &repo)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -54,8 +61,34 @@ func (s *Repo) Refs(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	var snapshots []database.Snapshot
+	if err := s.DB.Find(&snapshots, "repo_id = ?", repo.ID).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	pages := make(map[string]database.Snapshot)
+	for _, snap := range snapshots {
+		pages[snap.Name] = snap
+	}
+
+	releases, err := database.FindReleasesByRepoID(s.DB, repo.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	tagReleases := make(map[string]database.Release)
+	for _, release := range releases {
+		tagReleases[release.Tag] = release
+	}
+
 	data["User"] = user
 	data["Repo"] = repo
+	data["Role"] = repo.Role(s.DB, sess.Viewer())
+	data["Ref"] = refname
+	data["Pages"] = pages
+	data["Releases"] = tagReleases
 	data["Tags"] = tags
 	data["Branches"] = branches
 	data["Tab"] = RepoRefsTab
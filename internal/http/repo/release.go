@@ -0,0 +1,400 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/gorilla/mux"
+	cid "github.com/ipfs/go-cid"
+	ufsio "github.com/ipfs/go-unixfs/io"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/atom"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/gitutil"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/view"
+)
+
+// ReleaseUploadLimit is the maximum size of a release upload in bytes.
+const ReleaseUploadLimit = 1 << 30
+
+// releaseMemoryLimit is the amount of an upload kept in memory.
+// The rest is written to temporary files.
+const releaseMemoryLimit = 32 << 20
+
+var (
+	errReleaseTag     = errors.New("tag does not exist")
+	errReleaseExists  = errors.New("tag already has a release")
+	errEncryptedAsset = errors.New("assets cannot be added to encrypted repositories")
+)
+
+func (s *Repo) Releases(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	data := make(map[string]interface{})
+
+	sess, err := session.Get(req, s.DB)
+	if err == nil {
+		data["Session"] = sess
+	}
+
+	params := mux.Vars(req)
+	username := params["user"]
+	reponame := params["repo"]
+
+	var user database.User
+	if err := user.FindByUsername(s.DB, username); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var repo database.Repo
+	if err := repo.FindByNameAndUserID(s.DB, reponame, user.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !repo.CanRead(s.DB, sess.Viewer()) {
+		http.Error(w, errNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	releases, err := database.FindReleasesByRepoID(s.DB, repo.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	git, err := (*core.Server)(s).OpenRepo(ctx, &repo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	tags, err := gitutil.Tags(git)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data["User"] = user
+	data["Repo"] = repo
+	data["Role"] = repo.Role(s.DB, sess.Viewer())
+	data["Releases"] = releases
+	data["Tags"] = tags
+	data["Tab"] = RepoReleasesTab
+	view.Render(w, "repo.html", data)
+}
+
+func (s *Repo) CreateRelease(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	sess, user, repo, ok := s.findRepoWithRole(w, req, database.RoleWrite)
+	if !ok {
+		return
+	}
+
+	req.Body = http.MaxBytesReader(w, req.Body, ReleaseUploadLimit)
+	if err := req.ParseMultipartForm(releaseMemoryLimit); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer req.MultipartForm.RemoveAll()
+
+	files := req.MultipartForm.File["asset"]
+	if len(files) > 0 && repo.Encrypted {
+		http.Error(w, errEncryptedAsset.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tag := req.FormValue("tag")
+
+	git, err := (*core.Server)(s).OpenRepo(ctx, repo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := git.Reference(plumbing.NewTagReferenceName(tag), true); err != nil {
+		http.Error(w, errReleaseTag.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var existing database.Release
+	if err := existing.FindByTagAndRepoID(s.DB, tag, repo.ID); err == nil {
+		http.Error(w, errReleaseExists.Error(), http.StatusBadRequest)
+		return
+	}
+
+	release := database.Release{
+		RepoID: repo.ID,
+		Tag:    tag,
+		UserID: sess.UserID,
+		Title:  req.FormValue("title"),
+		Notes:  req.FormValue("notes"),
+	}
+
+	if err := release.Save(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.addAssets(req, &release, files); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	url := path.Join("/", user.Username, repo.Name, "releases")
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Repo) UploadAssets(w http.ResponseWriter, req *http.Request) {
+	_, user, repo, ok := s.findRepoWithRole(w, req, database.RoleWrite)
+	if !ok {
+		return
+	}
+
+	if repo.Encrypted {
+		http.Error(w, errEncryptedAsset.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var release database.Release
+	if err := release.FindByIDAndRepoID(s.DB, mux.Vars(req)["id"], repo.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	req.Body = http.MaxBytesReader(w, req.Body, ReleaseUploadLimit)
+	if err := req.ParseMultipartForm(releaseMemoryLimit); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer req.MultipartForm.RemoveAll()
+
+	if err := s.addAssets(req, &release, req.MultipartForm.File["asset"]); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	url := path.Join("/", user.Username, repo.Name, "releases")
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Repo) DeleteRelease(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	_, user, repo, ok := s.findRepoWithRole(w, req, database.RoleWrite)
+	if !ok {
+		return
+	}
+
+	var release database.Release
+	if err := release.FindByIDAndRepoID(s.DB, mux.Vars(req)["id"], repo.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	// acquire a pinlock so pins are not changed concurrently
+	defer s.Node.Blockstore.PinLock().Unlock()
+
+	if err := (*core.Server)(s).DeleteRelease(ctx, &release); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	url := path.Join("/", user.Username, repo.Name, "releases")
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Repo) DeleteAsset(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	params := mux.Vars(req)
+
+	_, user, repo, ok := s.findRepoWithRole(w, req, database.RoleWrite)
+	if !ok {
+		return
+	}
+
+	var release database.Release
+	if err := release.FindByIDAndRepoID(s.DB, params["id"], repo.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	var asset database.ReleaseAsset
+	if err := asset.FindByNameAndReleaseID(s.DB, params["name"], release.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	// acquire a pinlock so pins are not changed concurrently
+	defer s.Node.Blockstore.PinLock().Unlock()
+
+	if err := (*core.Server)(s).DeleteReleaseAsset(ctx, &asset); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	url := path.Join("/", user.Username, repo.Name, "releases")
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+// Asset serves the contents of a release asset from IPFS.
+func (s *Repo) Asset(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	sess, _ := session.Get(req, s.DB)
+
+	params := mux.Vars(req)
+	username := params["user"]
+	reponame := params["repo"]
+
+	var user database.User
+	if err := user.FindByUsername(s.DB, username); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var repo database.Repo
+	if err := repo.FindByNameAndUserID(s.DB, reponame, user.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !repo.CanRead(s.DB, sess.Viewer()) {
+		http.Error(w, errNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	var release database.Release
+	if err := release.FindByIDAndRepoID(s.DB, params["id"], repo.ID); err != nil {
+		http.NotFound(w, req)
+		return
+	}
+
+	var asset database.ReleaseAsset
+	if err := asset.FindByNameAndReleaseID(s.DB, params["name"], release.ID); err != nil {
+		http.NotFound(w, req)
+		return
+	}
+
+	id, err := cid.Decode(asset.CID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	node, err := s.Node.DAG.Get(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	r, err := ufsio.NewDagReader(ctx, node, s.Node.DAG)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer r.Close()
+
+	// assets are always downloaded so they cannot run in the site origin
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": asset.Name})
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", disposition)
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; sandbox")
+	w.Header().Set("X-Ipfs-Path", "/ipfs/"+asset.CID)
+	w.Header().Set("Etag", `"`+asset.CID+`"`)
+	http.ServeContent(w, req, "", asset.CreatedAt, r)
+}
+
+// ReleasesFeed writes an Atom feed of the repository releases.
+func (s *Repo) ReleasesFeed(w http.ResponseWriter, req *http.Request) {
+	sess, _ := session.Get(req, s.DB)
+
+	params := mux.Vars(req)
+	username := params["user"]
+	reponame := params["repo"]
+
+	var user database.User
+	if err := user.FindByUsername(s.DB, username); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var repo database.Repo
+	if err := repo.FindByNameAndUserID(s.DB, reponame, user.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !repo.CanRead(s.DB, sess.Viewer()) {
+		http.Error(w, errNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	releases, err := database.FindReleasesByRepoID(s.DB, repo.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	base := atom.BaseURL(req) + path.Join("/", user.Username, repo.Name, "releases")
+	feed := atom.Feed{
+		ID:      base,
+		Title:   fmt.Sprintf("Releases of %s/%s", user.Username, repo.Name),
+		Updated: repo.CreatedAt,
+		Links: []atom.Link{
+			{Href: base},
+			{Href: base + ".atom", Rel: "self"},
+		},
+	}
+
+	for _, release := range releases {
+		notes, err := view.RenderMarkdown(release.Notes)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		link := fmt.Sprintf("%s#release-%d", base, release.ID)
+		feed.Entries = append(feed.Entries, atom.Entry{
+			ID:      link,
+			Title:   release.Title,
+			Updated: release.UpdatedAt,
+			Author:  &atom.Person{Name: release.User.Username},
+			Links:   []atom.Link{{Href: link}},
+			Content: &atom.Text{Type: "html", Body: notes},
+		})
+	}
+
+	atom.Serve(w, req, &feed, repo.Visibility == database.RepoPublic)
+}
+
+// addAssets adds the uploaded files to IPFS and attaches them to the release.
+func (s *Repo) addAssets(req *http.Request, release *database.Release, files []*multipart.FileHeader) error {
+	// acquire a pinlock so GC doesn't remove assets before they are pinned
+	defer s.Node.Blockstore.PinLock().Unlock()
+
+	for _, header := range files {
+		file, err := header.Open()
+		if err != nil {
+			return err
+		}
+
+		_, err = (*core.Server)(s).AddReleaseAsset(req.Context(), release, path.Base(header.Filename), file)
+		file.Close()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -1,17 +1,55 @@
 package repo
 
 import (
+	"errors"
+	"net/http"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
 	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
 )
 
 // RepoLogsPerPage is the amount of logs per page.
 const RepoLogsPerPage = 30
 
+// RepoSearchPerPage is the amount of search results per page.
+const RepoSearchPerPage = 20
+
 const (
-	RepoInfoTab = "info"
-	RepoTreeTab = "tree"
-	RepoRefsTab = "refs"
-	RepoLogsTab = "logs"
+	RepoInfoTab     = "info"
+	RepoTreeTab     = "tree"
+	RepoRefsTab     = "refs"
+	RepoReleasesTab = "releases"
+	RepoLogsTab     = "logs"
+	RepoSearchTab   = "search"
+	RepoForksTab    = "forks"
+	RepoPullsTab    = "pulls"
+	RepoIssuesTab   = "issues"
+	RepoSettingsTab = "settings"
 )
 
+// errNotFound is returned for repositories the user cannot read.
+var errNotFound = errors.New("repository not found")
+
+// errOwner is returned when creating repositories for other accounts.
+var errOwner = errors.New("repositories can only be created for yourself or organizations you own")
+
+// errTransferOrg is returned when transferring repositories to organizations the user does not own.
+var errTransferOrg = errors.New("repositories can only be transferred to organizations you own")
+
 type Repo core.Server
+
+// gitStatus returns the status code for errors resolving refs and paths.
+func gitStatus(err error) int {
+	switch {
+	case errors.Is(err, plumbing.ErrReferenceNotFound),
+		errors.Is(err, plumbing.ErrObjectNotFound),
+		errors.Is(err, object.ErrEntryNotFound),
+		errors.Is(err, object.ErrDirectoryNotFound),
+		errors.Is(err, object.ErrFileNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,91 @@
+package repo
+
+import (
+	"errors"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/codesearch"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/view"
+)
+
+// errEncryptedSearch is shown on the search tab of encrypted repositories.
+var errEncryptedSearch = errors.New("code search is not available for encrypted repositories")
+
+func (s *Repo) Search(w http.ResponseWriter, req *http.Request) {
+	data := make(map[string]interface{})
+
+	sess, err := session.Get(req, s.DB)
+	if err == nil {
+		data["Session"] = sess
+	}
+
+	params := mux.Vars(req)
+	username := params["user"]
+	reponame := params["repo"]
+	query := req.URL.Query()
+	offset := query.Get("offset")
+
+	if offset == "" {
+		offset = "0"
+	}
+
+	offsetnum, err := strconv.ParseInt(offset, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var user database.User
+	if err := user.FindByUsername(s.DB, username); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var repo database.Repo
+	if err := repo.FindByNameAndUserID(s.DB, reponame, user.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !repo.CanRead(s.DB, sess.Viewer()) {
+		http.Error(w, errNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	q := codesearch.Query{
+		Text:     query.Get("q"),
+		Regex:    query.Get("regex") == "on",
+		Path:     query.Get("path"),
+		Language: query.Get("lang"),
+		RepoID:   repo.ID,
+		Offset:   int(offsetnum),
+		Limit:    RepoSearchPerPage,
+	}
+
+	if repo.Encrypted {
+		data["Error"] = errEncryptedSearch.Error()
+	} else if q.Text != "" {
+		results, err := codesearch.Search(req.Context(), s.DB, q)
+		if err != nil {
+			data["Error"] = err.Error()
+		}
+
+		data["Results"] = results
+	}
+
+	data["User"] = user
+	data["Repo"] = repo
+	data["Role"] = repo.Role(s.DB, sess.Viewer())
+	data["Query"] = q
+	data["Action"] = path.Join("/", username, reponame, "search")
+	data["Tab"] = RepoSearchTab
+	data["Next"] = offsetnum + RepoSearchPerPage
+	data["Prev"] = offsetnum - RepoSearchPerPage
+	view.Render(w, "repo.html", data)
+}
@@ -0,0 +1,188 @@
+package repo
+
+import (
+	"errors"
+	"net/http"
+	"path"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/view"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/webhook"
+)
+
+var errPermission = errors.New("you do not have permission to change repository settings")
+
+func (s *Repo) Settings(w http.ResponseWriter, req *http.Request) {
+	data := make(map[string]interface{})
+
+	sess, user, repo, ok := s.findRepoWithRole(w, req, database.RoleMaintain)
+	if !ok {
+		return
+	}
+
+	role := repo.Role(s.DB, sess.Viewer())
+	if database.HasRole(role, database.RoleAdmin) {
+		hooks, err := database.FindWebhooksByRepoID(s.DB, repo.ID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		deliveries, err := webhook.DeliveryLog(s.DB, hooks)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		collaborators, err := database.FindCollaboratorsByRepoID(s.DB, repo.ID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		data["Webhooks"] = hooks
+		data["Deliveries"] = deliveries
+		data["Events"] = database.WebhookEvents
+		data["Collaborators"] = collaborators
+		data["Roles"] = database.CollaboratorRoles
+	}
+
+	if database.HasRole(role, database.RoleOwner) {
+		var transfer database.Transfer
+		err := transfer.FindByRepoID(s.DB, repo.ID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if err == nil {
+			data["Transfer"] = transfer
+		}
+	}
+
+	data["Session"] = sess
+	data["User"] = user
+	data["Repo"] = repo
+	data["Role"] = role
+	data["Action"] = path.Join("/", user.Username, repo.Name, "settings")
+	data["Tab"] = RepoSettingsTab
+	view.Render(w, "repo.html", data)
+}
+
+func (s *Repo) CreateWebhook(w http.ResponseWriter, req *http.Request) {
+	_, user, repo, ok := s.findRepoWithRole(w, req, database.RoleAdmin)
+	if !ok {
+		return
+	}
+
+	hook, err := webhook.FromForm(req, s.Key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	hook.UserID = user.ID
+	hook.RepoID = repo.ID
+
+	if err := hook.Create(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	url := path.Join("/", user.Username, repo.Name, "settings")
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Repo) DeleteWebhook(w http.ResponseWriter, req *http.Request) {
+	_, user, repo, ok := s.findRepoWithRole(w, req, database.RoleAdmin)
+	if !ok {
+		return
+	}
+
+	var hook database.Webhook
+	if err := hook.FindByIDAndRepoID(s.DB, mux.Vars(req)["hook"], repo.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := hook.Delete(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	url := path.Join("/", user.Username, repo.Name, "settings")
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Repo) Redeliver(w http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+
+	_, user, repo, ok := s.findRepoWithRole(w, req, database.RoleAdmin)
+	if !ok {
+		return
+	}
+
+	var hook database.Webhook
+	if err := hook.FindByIDAndRepoID(s.DB, params["hook"], repo.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	var delivery database.Delivery
+	if err := delivery.FindByIDAndWebhookID(s.DB, params["id"], hook.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := webhook.Redeliver(s.DB, s.Key, &hook, &delivery); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	url := path.Join("/", user.Username, repo.Name, "settings")
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+// findRepoWithRole returns the session, owner and repo of the request.
+//
+// An error response is written if the session user does not have the role.
+// An empty role only requires the session user to be able to read the repo.
+func (s *Repo) findRepoWithRole(w http.ResponseWriter, req *http.Request, role string) (*database.Session, *database.User, *database.Repo, bool) {
+	params := mux.Vars(req)
+	username := params["user"]
+	reponame := params["repo"]
+
+	sess, err := session.Get(req, s.DB)
+	if err != nil {
+		http.Redirect(w, req, "/_log_in", http.StatusSeeOther)
+		return nil, nil, nil, false
+	}
+
+	var user database.User
+	if err := user.FindByUsername(s.DB, username); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, nil, nil, false
+	}
+
+	var repo database.Repo
+	if err := repo.FindByNameAndUserID(s.DB, reponame, user.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, nil, nil, false
+	}
+
+	if !repo.CanRead(s.DB, sess.Viewer()) {
+		http.Error(w, errNotFound.Error(), http.StatusNotFound)
+		return nil, nil, nil, false
+	}
+
+	if !repo.HasRole(s.DB, sess.Viewer(), role) {
+		http.Error(w, errPermission.Error(), http.StatusForbidden)
+		return nil, nil, nil, false
+	}
+
+	return sess, &user, &repo, true
+}
@@ -0,0 +1,159 @@
+package repo
+
+import (
+	"log"
+	"net/http"
+	"path"
+
+	"github.com/gorilla/mux"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
+)
+
+// starState is the relationship of the viewer with a repository.
+type starState struct {
+	// Starred is true if the viewer starred the repository.
+	Starred bool
+	// Watching is true if the viewer watches the repository.
+	Watching bool
+	// Watchers is the number of users watching the repository.
+	Watchers int64
+}
+
+func (s *Repo) Star(w http.ResponseWriter, req *http.Request) {
+	sess, user, repo, ok := s.findReadableRepo(w, req)
+	if !ok {
+		return
+	}
+
+	var star database.Star
+	if err := star.FindByUserIDAndRepoID(s.DB, sess.UserID, repo.ID); err != nil {
+		star = database.Star{UserID: sess.UserID, RepoID: repo.ID}
+
+		if err := star.Create(s.DB); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if err := (*core.Server)(s).RecordActivity(sess.UserID, repo, database.ActivityStar); err != nil {
+			log.Println(err)
+		}
+	}
+
+	url := path.Join("/", user.Username, repo.Name)
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Repo) Unstar(w http.ResponseWriter, req *http.Request) {
+	sess, user, repo, ok := s.findReadableRepo(w, req)
+	if !ok {
+		return
+	}
+
+	var star database.Star
+	if err := star.FindByUserIDAndRepoID(s.DB, sess.UserID, repo.ID); err == nil {
+		if err := star.Delete(s.DB); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	url := path.Join("/", user.Username, repo.Name)
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Repo) Watch(w http.ResponseWriter, req *http.Request) {
+	sess, user, repo, ok := s.findReadableRepo(w, req)
+	if !ok {
+		return
+	}
+
+	var watch database.Watch
+	if err := watch.FindByUserIDAndRepoID(s.DB, sess.UserID, repo.ID); err != nil {
+		watch = database.Watch{UserID: sess.UserID, RepoID: repo.ID}
+
+		if err := watch.Create(s.DB); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	url := path.Join("/", user.Username, repo.Name)
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Repo) Unwatch(w http.ResponseWriter, req *http.Request) {
+	sess, user, repo, ok := s.findReadableRepo(w, req)
+	if !ok {
+		return
+	}
+
+	var watch database.Watch
+	if err := watch.FindByUserIDAndRepoID(s.DB, sess.UserID, repo.ID); err == nil {
+		if err := watch.Delete(s.DB); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	url := path.Join("/", user.Username, repo.Name)
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+// findStarState returns the relationship of the viewer with the repo.
+//
+// A nil session is an anonymous visitor.
+func (s *Repo) findStarState(sess *database.Session, repo *database.Repo) (*starState, error) {
+	watchers, err := database.CountWatchesByRepoID(s.DB, repo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	state := starState{Watchers: watchers}
+	if sess == nil {
+		return &state, nil
+	}
+
+	var star database.Star
+	state.Starred = star.FindByUserIDAndRepoID(s.DB, sess.UserID, repo.ID) == nil
+
+	var watch database.Watch
+	state.Watching = watch.FindByUserIDAndRepoID(s.DB, sess.UserID, repo.ID) == nil
+
+	return &state, nil
+}
+
+// findReadableRepo returns the session and the repo from the request
+// if the user is logged in and can read the repo.
+func (s *Repo) findReadableRepo(w http.ResponseWriter, req *http.Request) (*database.Session, *database.User, *database.Repo, bool) {
+	params := mux.Vars(req)
+	username := params["user"]
+	reponame := params["repo"]
+
+	sess, err := session.Get(req, s.DB)
+	if err != nil {
+		http.Redirect(w, req, "/_log_in", http.StatusSeeOther)
+		return nil, nil, nil, false
+	}
+
+	var user database.User
+	if err := user.FindByUsername(s.DB, username); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, nil, nil, false
+	}
+
+	var repo database.Repo
+	if err := repo.FindByNameAndUserID(s.DB, reponame, user.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, nil, nil, false
+	}
+
+	if !repo.CanRead(s.DB, sess.Viewer()) {
+		http.Error(w, errNotFound.Error(), http.StatusNotFound)
+		return nil, nil, nil, false
+	}
+
+	return sess, &user, &repo, true
+}
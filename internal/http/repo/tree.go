@@ -2,10 +2,12 @@ package repo
 
 import (
 	"net/http"
+	"path"
 
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/gorilla/mux"
 
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
 	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
 	"github.com/multiverse-vcs/go-git-ipfs/internal/gitutil"
 	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
@@ -25,6 +27,8 @@ func (s *Repo) Tree(w http.ResponseWriter, req *http.Request) {
 	username := params["user"]
 	reponame := params["repo"]
 	refpath := params["refpath"]
+	refname := req.URL.Query().Get("ref")
+	fpath := req.URL.Query().Get("path")
 
 	var user database.User
 	if err := user.FindByUsername(s.DB, username); err != nil {
@@ -38,7 +42,12 @@ func (s *Repo) Tree(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	git, err := gitutil.Open(ctx, s.Node.DAG, repo.CID)
+	if !repo.CanRead(s.DB, sess.Viewer()) {
+		http.Error(w, errNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	git, err := (*core.Server)(s).OpenRepo(ctx, &repo)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -55,33 +64,84 @@ func (s *Repo) Tree(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if refpath == "" && refname != "" {
+		ref, err := gitutil.Resolve(git, refname)
+		if err != nil {
+			http.Error(w, err.Error(), gitStatus(err))
+			return
+		}
+
+		url := path.Join("/", username, reponame, "tree", ref.Name().String(), fpath)
+		http.Redirect(w, req, url, http.StatusSeeOther)
+		return
+	}
+
 	if refpath == "" {
 		refpath = head.Name().String()
 	}
 
-	ref, path, err := gitutil.RefPath(git, refpath)
+	branches, err := gitutil.Branches(git)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	obj, err := gitutil.Find(git, ref, path)
+	tags, err := gitutil.Tags(git)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	ref, subpath, err := gitutil.RefPath(git, refpath)
+	if err != nil {
+		http.Error(w, err.Error(), gitStatus(err))
+		return
+	}
+
+	obj, err := gitutil.Find(git, ref, subpath)
+	if err != nil {
+		http.Error(w, err.Error(), gitStatus(err))
+		return
+	}
+
 	switch o := obj.(type) {
 	case *object.Tree:
+		readme, err := gitutil.Readme(o)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
 		data["Tree"] = o
+		data["Readme"] = readme
 	case *object.Blob:
+		kind, err := blobKind(subpath, o)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if kind == BlobLargeKind {
+			preview, err := blobPreview(o)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			data["Preview"] = preview
+		}
+
 		data["Blob"] = o
+		data["Kind"] = kind
 	}
 
 	data["User"] = user
 	data["Repo"] = repo
+	data["Role"] = repo.Role(s.DB, sess.Viewer())
 	data["Ref"] = ref.Name().String()
-	data["Path"] = path
+	data["Path"] = subpath
+	data["Tags"] = tags
+	data["Branches"] = branches
 	data["Tab"] = RepoTreeTab
 	view.Render(w, "repo.html", data)
 }
@@ -0,0 +1,57 @@
+package search
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/codesearch"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/view"
+)
+
+func (s *Search) Read(w http.ResponseWriter, req *http.Request) {
+	data := make(map[string]interface{})
+
+	sess, err := session.Get(req, s.DB)
+	if err == nil {
+		data["Session"] = sess
+	}
+
+	query := req.URL.Query()
+	offset := query.Get("offset")
+
+	if offset == "" {
+		offset = "0"
+	}
+
+	offsetnum, err := strconv.ParseInt(offset, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	q := codesearch.Query{
+		Text:     query.Get("q"),
+		Regex:    query.Get("regex") == "on",
+		Path:     query.Get("path"),
+		Language: query.Get("lang"),
+		Viewer:   sess.Viewer(),
+		Offset:   int(offsetnum),
+		Limit:    SearchPerPage,
+	}
+
+	if q.Text != "" {
+		results, err := codesearch.Search(req.Context(), s.DB, q)
+		if err != nil {
+			data["Error"] = err.Error()
+		}
+
+		data["Results"] = results
+	}
+
+	data["Query"] = q
+	data["Action"] = "/_search"
+	data["Next"] = offsetnum + SearchPerPage
+	data["Prev"] = offsetnum - SearchPerPage
+	view.Render(w, "search.html", data)
+}
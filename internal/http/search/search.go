@@ -0,0 +1,10 @@
+package search
+
+import (
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
+)
+
+// SearchPerPage is the amount of search results per page.
+const SearchPerPage = 20
+
+type Search core.Server
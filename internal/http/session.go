@@ -0,0 +1,25 @@
+package http
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
+)
+
+// renewSessions extends the session of active users so that
+// only sessions left unused for a whole lifetime expire.
+func renewSessions(db *gorm.DB) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if err := session.Renew(w, req, db); err != nil {
+				log.Println(err)
+			}
+
+			next.ServeHTTP(w, req)
+		})
+	}
+}
@@ -2,6 +2,9 @@ package session
 
 import (
 	"net/http"
+	"os"
+	"strconv"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -11,6 +14,22 @@ import (
 // CookieName is the name of the http cookie.
 const CookieName = "session"
 
+// SecureEnv marks session cookies as secure when set to true. It must be
+// set when a proxy terminates TLS, because requests then reach the server
+// over plain http.
+const SecureEnv = "MULTIVERSE_SECURE_COOKIES"
+
+const (
+	// Lifetime is how long a session lasts without being used.
+	Lifetime = 14 * 24 * time.Hour
+	// RenewInterval is the minimum time between renewals of a session,
+	// so active sessions are not written on every request.
+	RenewInterval = time.Hour
+)
+
+// userAgentLimit is the number of user agent bytes stored with a session.
+const userAgentLimit = 256
+
 // Get returns the session from the cookie.
 func Get(req *http.Request, db *gorm.DB) (*database.Session, error) {
 	cookie, err := req.Cookie(CookieName)
@@ -26,28 +45,88 @@ func Get(req *http.Request, db *gorm.DB) (*database.Session, error) {
 	return &sess, nil
 }
 
-// Set writes the session cookie.
-func Set(w http.ResponseWriter, db *gorm.DB, sess *database.Session) error {
+// Set creates the session and writes the session cookie.
+func Set(w http.ResponseWriter, req *http.Request, db *gorm.DB, sess *database.Session) error {
+	sess.UserAgent = req.UserAgent()
+	if len(sess.UserAgent) > userAgentLimit {
+		sess.UserAgent = sess.UserAgent[:userAgentLimit]
+	}
+
+	sess.ExpiresAt = time.Now().Add(Lifetime)
 	if err := sess.Create(db); err != nil {
 		return err
 	}
 
-	cookie := http.Cookie{
-		Name:     CookieName,
-		Value:    sess.ID,
-		HttpOnly: true,
+	writeCookie(w, req, sess)
+	return nil
+}
+
+// Renew extends the session from the cookie by another lifetime
+// if it was not renewed within the renew interval.
+func Renew(w http.ResponseWriter, req *http.Request, db *gorm.DB) error {
+	sess, err := Get(req, db)
+	if err != nil {
+		return nil
 	}
 
-	http.SetCookie(w, &cookie)
+	if time.Since(sess.UpdatedAt) < RenewInterval {
+		return nil
+	}
+
+	if err := sess.Renew(db, time.Now().Add(Lifetime)); err != nil {
+		return err
+	}
+
+	writeCookie(w, req, sess)
 	return nil
 }
 
+// Delete removes the session from the cookie and clears the cookie.
+func Delete(w http.ResponseWriter, req *http.Request, db *gorm.DB) error {
+	defer Clear(w)
+
+	cookie, err := req.Cookie(CookieName)
+	if err != nil || cookie.Value == "" {
+		return nil
+	}
+
+	sess := database.Session{ID: cookie.Value}
+	return sess.Delete(db)
+}
+
 // Clear removes the session cookie.
 func Clear(w http.ResponseWriter) {
 	cookie := http.Cookie{
 		Name:   CookieName,
+		Path:   "/",
 		MaxAge: -1,
 	}
 
 	http.SetCookie(w, &cookie)
 }
+
+// writeCookie writes the session cookie expiring with the session.
+//
+// Cookies are marked secure when served over TLS or when enabled by
+// the environment, so the server keeps working on plain http during
+// development.
+func writeCookie(w http.ResponseWriter, req *http.Request, sess *database.Session) {
+	cookie := http.Cookie{
+		Name:     CookieName,
+		Value:    sess.ID,
+		Path:     "/",
+		Expires:  sess.ExpiresAt,
+		MaxAge:   int(time.Until(sess.ExpiresAt).Seconds()),
+		HttpOnly: true,
+		Secure:   req.TLS != nil || secureCookies(),
+		SameSite: http.SameSiteLaxMode,
+	}
+
+	http.SetCookie(w, &cookie)
+}
+
+// secureCookies returns true if secure cookies are enabled by the environment.
+func secureCookies() bool {
+	secure, _ := strconv.ParseBool(os.Getenv(SecureEnv))
+	return secure
+}
@@ -0,0 +1,123 @@
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	db, err := database.Open(sqlite.Open("file::memory:"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get database: %v", err)
+	}
+
+	// every connection would open a separate in-memory database
+	sqlDB.SetMaxOpenConns(1)
+
+	db.Logger = logger.Discard
+	return db
+}
+
+func createTestSession(t *testing.T, db *gorm.DB, req *http.Request) (*database.Session, *http.Cookie) {
+	user := database.User{Username: "alice", Email: "alice@example.com", Password: "password"}
+	if err := user.Create(db); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	sess := database.Session{UserID: user.ID}
+	if err := Set(w, req, db, &sess); err != nil {
+		t.Fatalf("failed to set session: %v", err)
+	}
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("got %d cookies, want 1", len(cookies))
+	}
+
+	return &sess, cookies[0]
+}
+
+func TestSecureCookie(t *testing.T) {
+	tests := []struct {
+		env    string
+		tls    bool
+		secure bool
+	}{
+		{"", false, false},
+		{"", true, true},
+		{"false", false, false},
+		{"true", false, true},
+		{"1", false, true},
+	}
+
+	defer os.Unsetenv(SecureEnv)
+
+	for _, test := range tests {
+		os.Setenv(SecureEnv, test.env)
+
+		req := httptest.NewRequest(http.MethodPost, "/_log_in", nil)
+		if test.tls {
+			req = httptest.NewRequest(http.MethodPost, "https://example.com/_log_in", nil)
+		}
+
+		_, cookie := createTestSession(t, openTestDB(t), req)
+		if cookie.Secure != test.secure {
+			t.Errorf("Secure = %t with %s=%q and TLS %t, want %t", cookie.Secure, SecureEnv, test.env, test.tls, test.secure)
+		}
+
+		if !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode {
+			t.Errorf("cookie must be http only and same site lax: %+v", cookie)
+		}
+	}
+}
+
+func TestDelete(t *testing.T) {
+	db := openTestDB(t)
+	sess, cookie := createTestSession(t, db, httptest.NewRequest(http.MethodPost, "/_log_in", nil))
+
+	tests := []struct {
+		name   string
+		cookie *http.Cookie
+	}{
+		{"no cookie", nil},
+		{"empty cookie", &http.Cookie{Name: CookieName}},
+		{"unknown session", &http.Cookie{Name: CookieName, Value: "unknown"}},
+		{"session", cookie},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/_log_out", nil)
+		if test.cookie != nil {
+			req.AddCookie(test.cookie)
+		}
+
+		w := httptest.NewRecorder()
+		if err := Delete(w, req, db); err != nil {
+			t.Errorf("%s: failed to delete session: %v", test.name, err)
+		}
+
+		cookies := w.Result().Cookies()
+		if len(cookies) != 1 || cookies[0].MaxAge >= 0 {
+			t.Errorf("%s: cookie was not cleared: %+v", test.name, cookies)
+		}
+	}
+
+	var deleted database.Session
+	if err := deleted.Find(db, sess.ID); err == nil {
+		t.Error("session was not deleted")
+	}
+}
@@ -0,0 +1,84 @@
+package settings
+
+import (
+	"errors"
+	"net/http"
+
+	"gorm.io/gorm"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
+)
+
+var (
+	errPassword   = errors.New("password is incorrect")
+	errEmailTaken = errors.New("email address is already in use")
+)
+
+// EditEmail changes the email address of the user after checking their
+// password and sends a verification link to the new address.
+func (s *Settings) EditEmail(w http.ResponseWriter, req *http.Request) {
+	sess, err := session.Get(req, s.DB)
+	if err != nil {
+		http.Redirect(w, req, "/_log_in", http.StatusSeeOther)
+		return
+	}
+
+	email := req.FormValue("email")
+	password := req.FormValue("password")
+
+	user := sess.User
+	if err := user.CheckPassword(password); err != nil {
+		http.Error(w, errPassword.Error(), http.StatusForbidden)
+		return
+	}
+
+	if email == user.Email {
+		http.Redirect(w, req, "/_settings", http.StatusSeeOther)
+		return
+	}
+
+	var other database.User
+	if err := other.FindByEmail(s.DB, email); err != gorm.ErrRecordNotFound {
+		http.Error(w, errEmailTaken.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user.Email = email
+	if err := user.UpdateEmail(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = (*core.Server)(s).SendEmailVerification(&user)
+	if err != nil && err != core.ErrMailDisabled {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, req, "/_settings", http.StatusSeeOther)
+}
+
+// ResendVerification sends a new verification link to the email address of the user.
+func (s *Settings) ResendVerification(w http.ResponseWriter, req *http.Request) {
+	sess, err := session.Get(req, s.DB)
+	if err != nil {
+		http.Redirect(w, req, "/_log_in", http.StatusSeeOther)
+		return
+	}
+
+	if sess.User.EmailVerified {
+		http.Redirect(w, req, "/_settings", http.StatusSeeOther)
+		return
+	}
+
+	switch err := (*core.Server)(s).SendEmailVerification(&sess.User); err {
+	case nil:
+		http.Redirect(w, req, "/_settings", http.StatusSeeOther)
+	case core.ErrMailDisabled:
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
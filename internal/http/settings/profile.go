@@ -0,0 +1,97 @@
+package settings
+
+import (
+	"net/http"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
+)
+
+// AvatarUploadLimit is the maximum size of an avatar upload in bytes.
+const AvatarUploadLimit = 1 << 20
+
+func (s *Settings) EditProfile(w http.ResponseWriter, req *http.Request) {
+	sess, err := session.Get(req, s.DB)
+	if err != nil {
+		http.Redirect(w, req, "/_log_in", http.StatusSeeOther)
+		return
+	}
+
+	var user database.User
+	if err := user.Find(s.DB, sess.UserID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	user.DisplayName = req.FormValue("display_name")
+	user.Bio = req.FormValue("bio")
+	user.Website = req.FormValue("website")
+
+	if err := user.UpdateProfile(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, req, "/_settings", http.StatusSeeOther)
+}
+
+func (s *Settings) EditNotifications(w http.ResponseWriter, req *http.Request) {
+	sess, err := session.Get(req, s.DB)
+	if err != nil {
+		http.Redirect(w, req, "/_log_in", http.StatusSeeOther)
+		return
+	}
+
+	user := sess.User
+	user.EmailNotifications = req.FormValue("email") == "on"
+
+	if err := user.UpdateEmailNotifications(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, req, "/_settings", http.StatusSeeOther)
+}
+
+func (s *Settings) EditAvatar(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	sess, err := session.Get(req, s.DB)
+	if err != nil {
+		http.Redirect(w, req, "/_log_in", http.StatusSeeOther)
+		return
+	}
+
+	req.Body = http.MaxBytesReader(w, req.Body, AvatarUploadLimit)
+	if err := req.ParseMultipartForm(AvatarUploadLimit); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer req.MultipartForm.RemoveAll()
+
+	file, _, err := req.FormFile("avatar")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	var user database.User
+	if err := user.Find(s.DB, sess.UserID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// acquire a pinlock so pins are not changed concurrently
+	defer s.Node.Blockstore.PinLock().Unlock()
+
+	switch err := (*core.Server)(s).SetAvatar(ctx, &user, file); err {
+	case nil:
+		http.Redirect(w, req, "/_settings", http.StatusSeeOther)
+	case core.ErrAvatarType:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,54 @@
+package settings
+
+import (
+	"net/http"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/view"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/webhook"
+)
+
+func (s *Settings) Read(w http.ResponseWriter, req *http.Request) {
+	data := make(map[string]interface{})
+
+	sess, err := session.Get(req, s.DB)
+	if err != nil {
+		http.Redirect(w, req, "/_log_in", http.StatusSeeOther)
+		return
+	}
+
+	var user database.User
+	if err := user.Find(s.DB, sess.UserID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	hooks, err := database.FindWebhooksByUserID(s.DB, sess.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	deliveries, err := webhook.DeliveryLog(s.DB, hooks)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	transfers, err := database.FindTransfersByUserID(s.DB, sess.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data["Session"] = sess
+	data["User"] = user
+	data["Transfers"] = transfers
+	data["Mailer"] = s.Mailer != nil
+	data["Webhooks"] = hooks
+	data["Deliveries"] = deliveries
+	data["Events"] = database.WebhookEvents
+	data["Action"] = "/_settings"
+	view.Render(w, "settings.html", data)
+}
@@ -0,0 +1,98 @@
+package settings
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/view"
+)
+
+var errSessionNotFound = errors.New("session not found")
+
+// Sessions lists the signed in sessions of the user.
+func (s *Settings) Sessions(w http.ResponseWriter, req *http.Request) {
+	data := make(map[string]interface{})
+
+	sess, err := session.Get(req, s.DB)
+	if err != nil {
+		http.Redirect(w, req, "/_log_in", http.StatusSeeOther)
+		return
+	}
+
+	sessions, err := database.FindSessionsByUserID(s.DB, sess.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data["Session"] = sess
+	data["Sessions"] = sessions
+	data["Current"] = sess.PublicID()
+	view.Render(w, "sessions.html", data)
+}
+
+// RevokeSession signs the user out of one of their sessions.
+func (s *Settings) RevokeSession(w http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+
+	sess, err := session.Get(req, s.DB)
+	if err != nil {
+		http.Redirect(w, req, "/_log_in", http.StatusSeeOther)
+		return
+	}
+
+	sessions, err := database.FindSessionsByUserID(s.DB, sess.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var target *database.Session
+	for i := range sessions {
+		if sessions[i].PublicID() == params["id"] {
+			target = &sessions[i]
+		}
+	}
+
+	if target == nil {
+		http.Error(w, errSessionNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	if target.ID == sess.ID {
+		if err := session.Delete(w, req, s.DB); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		http.Redirect(w, req, "/", http.StatusSeeOther)
+		return
+	}
+
+	if err := target.Delete(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, req, "/_settings/sessions", http.StatusSeeOther)
+}
+
+// RevokeOtherSessions signs the user out everywhere except the current session.
+func (s *Settings) RevokeOtherSessions(w http.ResponseWriter, req *http.Request) {
+	sess, err := session.Get(req, s.DB)
+	if err != nil {
+		http.Redirect(w, req, "/_log_in", http.StatusSeeOther)
+		return
+	}
+
+	if err := database.DeleteOtherSessions(s.DB, sess.UserID, sess.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, req, "/_settings/sessions", http.StatusSeeOther)
+}
@@ -0,0 +1,7 @@
+package settings
+
+import (
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
+)
+
+type Settings core.Server
@@ -0,0 +1,66 @@
+package settings
+
+import (
+	"errors"
+	"net/http"
+	"path"
+
+	"github.com/gorilla/mux"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
+)
+
+var errRepoExists = errors.New("you already have a repository with this name")
+
+func (s *Settings) AcceptTransfer(w http.ResponseWriter, req *http.Request) {
+	sess, err := session.Get(req, s.DB)
+	if err != nil {
+		http.Redirect(w, req, "/_log_in", http.StatusSeeOther)
+		return
+	}
+
+	var transfer database.Transfer
+	if err := transfer.FindByIDAndUserID(s.DB, mux.Vars(req)["id"], sess.UserID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	repo := transfer.Repo
+
+	var other database.Repo
+	if err := other.FindByNameAndUserID(s.DB, repo.Name, sess.UserID); err == nil {
+		http.Error(w, errRepoExists.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// moving the repo also removes the transfer
+	if err := repo.Move(s.DB, repo.Name, sess.UserID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	url := path.Join("/", sess.User.Username, repo.Name)
+	http.Redirect(w, req, url, http.StatusSeeOther)
+}
+
+func (s *Settings) DeclineTransfer(w http.ResponseWriter, req *http.Request) {
+	sess, err := session.Get(req, s.DB)
+	if err != nil {
+		http.Redirect(w, req, "/_log_in", http.StatusSeeOther)
+		return
+	}
+
+	var transfer database.Transfer
+	if err := transfer.FindByIDAndUserID(s.DB, mux.Vars(req)["id"], sess.UserID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := transfer.Delete(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, req, "/_settings", http.StatusSeeOther)
+}
@@ -0,0 +1,84 @@
+package settings
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/webhook"
+)
+
+func (s *Settings) CreateWebhook(w http.ResponseWriter, req *http.Request) {
+	sess, err := session.Get(req, s.DB)
+	if err != nil {
+		http.Redirect(w, req, "/_log_in", http.StatusSeeOther)
+		return
+	}
+
+	hook, err := webhook.FromForm(req, s.Key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	hook.UserID = sess.UserID
+
+	if err := hook.Create(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, req, "/_settings", http.StatusSeeOther)
+}
+
+func (s *Settings) DeleteWebhook(w http.ResponseWriter, req *http.Request) {
+	sess, err := session.Get(req, s.DB)
+	if err != nil {
+		http.Redirect(w, req, "/_log_in", http.StatusSeeOther)
+		return
+	}
+
+	var hook database.Webhook
+	if err := hook.FindByIDAndUserID(s.DB, mux.Vars(req)["hook"], sess.UserID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := hook.Delete(s.DB); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, req, "/_settings", http.StatusSeeOther)
+}
+
+func (s *Settings) Redeliver(w http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+
+	sess, err := session.Get(req, s.DB)
+	if err != nil {
+		http.Redirect(w, req, "/_log_in", http.StatusSeeOther)
+		return
+	}
+
+	var hook database.Webhook
+	if err := hook.FindByIDAndUserID(s.DB, params["hook"], sess.UserID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	var delivery database.Delivery
+	if err := delivery.FindByIDAndWebhookID(s.DB, params["id"], hook.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := webhook.Redeliver(s.DB, s.Key, &hook, &delivery); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, req, "/_settings", http.StatusSeeOther)
+}
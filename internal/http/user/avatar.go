@@ -0,0 +1,76 @@
+package user
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	cid "github.com/ipfs/go-cid"
+	ufsio "github.com/ipfs/go-unixfs/io"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+)
+
+// Avatar serves the avatar image of a user from IPFS.
+func (s *User) Avatar(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	params := mux.Vars(req)
+	username := params["user"]
+
+	var user database.User
+	if err := user.FindByUsername(s.DB, username); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if user.AvatarCID == "" {
+		http.NotFound(w, req)
+		return
+	}
+
+	id, err := cid.Decode(user.AvatarCID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	node, err := s.Node.DAG.Get(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	r, err := ufsio.NewDagReader(ctx, node, s.Node.DAG)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer r.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// avatars were checked on upload but never serve anything else as an image
+	ctype := http.DetectContentType(head[:n])
+	if !core.IsAvatarType(ctype) {
+		ctype = "application/octet-stream"
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", ctype)
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; sandbox")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("X-Ipfs-Path", "/ipfs/"+user.AvatarCID)
+	w.Header().Set("Etag", `"`+user.AvatarCID+`"`)
+	http.ServeContent(w, req, "", user.UpdatedAt, r)
+}
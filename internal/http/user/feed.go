@@ -0,0 +1,103 @@
+package user
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/gorilla/mux"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/atom"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/http/session"
+)
+
+// UserFeedEntries is the amount of entries in user feeds.
+const UserFeedEntries = 30
+
+// Feed writes an Atom feed of the activity of a user
+// on repositories the viewer can read.
+func (s *User) Feed(w http.ResponseWriter, req *http.Request) {
+	sess, _ := session.Get(req, s.DB)
+
+	params := mux.Vars(req)
+	username := params["user"]
+
+	var user database.User
+	if err := user.FindByUsername(s.DB, username); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	activities, err := database.FindActivitiesByUserID(s.DB, user.ID, sess.Viewer(), UserFeedEntries)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	base := atom.BaseURL(req)
+	profile := base + path.Join("/", user.Username)
+	feed := atom.Feed{
+		ID:      profile + ".atom",
+		Title:   fmt.Sprintf("Activity of %s", user.Username),
+		Updated: user.CreatedAt,
+		Links: []atom.Link{
+			{Href: profile},
+			{Href: profile + ".atom", Rel: "self"},
+		},
+	}
+
+	for _, activity := range activities {
+		var content string
+		if activity.Type == database.ActivityPush {
+			content = fmt.Sprintf("%s %s..%s", activity.Ref, activity.Old, activity.New)
+		}
+
+		link := base + activityLink(&activity)
+		feed.Entries = append(feed.Entries, atom.Entry{
+			ID:      fmt.Sprintf("%s#activity-%d", profile, activity.ID),
+			Title:   activityTitle(&user, &activity),
+			Updated: activity.CreatedAt,
+			Author:  &atom.Person{Name: user.Username},
+			Links:   []atom.Link{{Href: link}},
+			Content: &atom.Text{Type: "text", Body: content},
+		})
+	}
+
+	atom.Serve(w, req, &feed, sess == nil)
+}
+
+// activityTitle returns a summary of the activity of the user.
+func activityTitle(user *database.User, activity *database.Activity) string {
+	repo := path.Join(activity.Repo.User.Username, activity.Repo.Name)
+	ref := plumbing.ReferenceName(activity.Ref)
+
+	switch {
+	case activity.Type == database.ActivityCreate:
+		return fmt.Sprintf("%s created %s", user.Username, repo)
+	case activity.Type == database.ActivityFork && activity.Repo.Parent != nil:
+		parent := path.Join(activity.Repo.Parent.User.Username, activity.Repo.Parent.Name)
+		return fmt.Sprintf("%s forked %s to %s", user.Username, parent, repo)
+	case activity.Type == database.ActivityFork:
+		return fmt.Sprintf("%s forked %s", user.Username, repo)
+	case activity.Type == database.ActivityStar:
+		return fmt.Sprintf("%s starred %s", user.Username, repo)
+	case plumbing.NewHash(activity.New).IsZero():
+		return fmt.Sprintf("%s deleted %s in %s", user.Username, ref.Short(), repo)
+	case plumbing.NewHash(activity.Old).IsZero():
+		return fmt.Sprintf("%s created %s in %s", user.Username, ref.Short(), repo)
+	default:
+		return fmt.Sprintf("%s pushed %d commits to %s in %s", user.Username, activity.Commits, ref.Short(), repo)
+	}
+}
+
+// activityLink returns the path of the page showing the result of the activity.
+func activityLink(activity *database.Activity) string {
+	repo := path.Join("/", activity.Repo.User.Username, activity.Repo.Name)
+	if activity.Type != database.ActivityPush || plumbing.NewHash(activity.New).IsZero() {
+		return repo
+	}
+
+	return path.Join(repo, "tree", activity.New)
+}
@@ -0,0 +1,61 @@
+package user
+
+import (
+	"time"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+)
+
+// HeatmapWeeks is the number of weeks shown in the contribution heatmap.
+const HeatmapWeeks = 53
+
+// heatmapDay is a cell of the contribution heatmap.
+type heatmapDay struct {
+	// Date is the day in UTC.
+	Date time.Time
+	// Count is the number of contributions on the day.
+	Count int
+	// Level is the intensity from 0 to 4 relative to the busiest day.
+	Level int
+}
+
+// heatmap returns the contributions of the activities per day as rows
+// of weekdays, starting on Sunday, with one column per week until today.
+//
+// Pushes count their commits and all other activities count once.
+func heatmap(activities []database.Activity, now time.Time) [][]heatmapDay {
+	today := now.UTC().Truncate(24 * time.Hour)
+	start := today.AddDate(0, 0, -int(today.Weekday())-7*(HeatmapWeeks-1))
+
+	counts := make(map[time.Time]int)
+	for _, activity := range activities {
+		day := activity.CreatedAt.UTC().Truncate(24 * time.Hour)
+		if activity.Type == database.ActivityPush {
+			counts[day] += activity.Commits
+		} else {
+			counts[day]++
+		}
+	}
+
+	max := 0
+	for _, count := range counts {
+		if count > max {
+			max = count
+		}
+	}
+
+	rows := make([][]heatmapDay, 7)
+	for day := start; !day.After(today); day = day.AddDate(0, 0, 1) {
+		count := counts[day]
+
+		level := 0
+		if count > 0 {
+			level = 1 + 3*count/max
+		}
+
+		weekday := day.Weekday()
+		rows[weekday] = append(rows[weekday], heatmapDay{Date: day, Count: count, Level: level})
+	}
+
+	return rows
+}
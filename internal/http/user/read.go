@@ -2,6 +2,7 @@ package user
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -28,12 +29,75 @@ func (s *User) Read(w http.ResponseWriter, req *http.Request) {
 	}
 
 	var repos []database.Repo
-	if err := s.DB.Find(&repos, "user_id = ?", user.ID).Error; err != nil {
+	if err := s.DB.Preload("User").Scopes(database.VisibleRepos(sess.Viewer())).Find(&repos, "user_id = ?", user.ID).Error; err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	data["User"] = user
 	data["Repos"] = repos
-	view.Render(w, "user.html", data)
+
+	if !user.Org {
+		tab := req.URL.Query().Get("tab")
+		if tab == UserStarredTab {
+			repos, err = database.FindStarredRepos(s.DB, user.ID, sess.Viewer())
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			data["Repos"] = repos
+		} else {
+			tab = UserReposTab
+		}
+
+		activities, err := database.FindActivitiesByUserID(s.DB, user.ID, sess.Viewer(), UserFeedEntries)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		now := time.Now()
+		since := now.AddDate(0, 0, -7*HeatmapWeeks)
+
+		contributions, err := database.FindActivitiesSince(s.DB, user.ID, sess.Viewer(), since)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		timeline := make([]activityItem, len(activities))
+		for i := range activities {
+			timeline[i] = activityItem{
+				Activity: activities[i],
+				Title:    activityTitle(&user, &activities[i]),
+				Link:     activityLink(&activities[i]),
+			}
+		}
+
+		data["Activities"] = timeline
+		data["Heatmap"] = heatmap(contributions, now)
+		data["Tab"] = tab
+		view.Render(w, "user.html", data)
+		return
+	}
+
+	members, err := database.FindMembersByOrgID(s.DB, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data["Members"] = members
+	data["IsOwner"] = sess != nil && database.IsOrgOwner(s.DB, user.ID, sess.UserID)
+	view.Render(w, "org.html", data)
+}
+
+// activityItem is an activity with its summary for the profile timeline.
+type activityItem struct {
+	database.Activity
+	// Title is the summary of the activity.
+	Title string
+	// Link is the path of the page showing the result of the activity.
+	Link string
 }
@@ -4,4 +4,9 @@ import (
 	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
 )
 
+const (
+	UserReposTab   = "repos"
+	UserStarredTab = "starred"
+)
+
 type User core.Server
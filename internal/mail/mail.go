@@ -0,0 +1,152 @@
+package mail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// AddrEnv is the environment variable containing the SMTP server address.
+	AddrEnv = "MULTIVERSE_SMTP_ADDR"
+	// FromEnv is the environment variable containing the sender address.
+	FromEnv = "MULTIVERSE_SMTP_FROM"
+	// UsernameEnv is the environment variable containing the SMTP username.
+	UsernameEnv = "MULTIVERSE_SMTP_USERNAME"
+	// PasswordEnv is the environment variable containing the SMTP password.
+	PasswordEnv = "MULTIVERSE_SMTP_PASSWORD"
+	// BaseURLEnv is the environment variable containing the public URL
+	// of the server. Links in emails are relative to it.
+	BaseURLEnv = "MULTIVERSE_BASE_URL"
+)
+
+// DefaultFrom is the sender address used when none is configured.
+const DefaultFrom = "multiverse@localhost"
+
+var (
+	errHeader  = errors.New("mail headers cannot contain line breaks")
+	errBaseURL = errors.New(BaseURLEnv + " must be an absolute http or https URL without query")
+	errNoBase  = errors.New(BaseURLEnv + " must be set when " + AddrEnv + " is set")
+)
+
+// Message is a plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends email messages.
+type Mailer interface {
+	Send(msg *Message) error
+}
+
+// SMTP sends messages through an SMTP server.
+//
+// Authentication is skipped when the username is empty, so a local SMTP
+// stand-in can be used for development and tests.
+type SMTP struct {
+	// Addr is the host and port of the server.
+	Addr string
+	// From is the sender address.
+	From string
+	// Username is used for PLAIN authentication.
+	Username string
+	// Password is used for PLAIN authentication.
+	Password string
+}
+
+// NewFromEnv returns an SMTP mailer configured from the environment,
+// or nil if no server address is set.
+func NewFromEnv() Mailer {
+	addr := os.Getenv(AddrEnv)
+	if addr == "" {
+		return nil
+	}
+
+	from := os.Getenv(FromEnv)
+	if from == "" {
+		from = DefaultFrom
+	}
+
+	return &SMTP{
+		Addr:     addr,
+		From:     from,
+		Username: os.Getenv(UsernameEnv),
+		Password: os.Getenv(PasswordEnv),
+	}
+}
+
+// BaseURLFromEnv returns the public URL of the server from the environment
+// without a trailing slash. An error is returned if it is invalid, or if
+// it is missing while an SMTP server is configured.
+//
+// The URL is configured rather than taken from requests because the Host
+// header is chosen by the client and links in emails must not be.
+func BaseURLFromEnv() (string, error) {
+	base := os.Getenv(BaseURLEnv)
+	if base == "" {
+		if os.Getenv(AddrEnv) != "" {
+			return "", errNoBase
+		}
+
+		return "", nil
+	}
+
+	u, err := url.Parse(base)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+		return "", errBaseURL
+	}
+
+	return strings.TrimSuffix(base, "/"), nil
+}
+
+// Send delivers the message to the server.
+func (m *SMTP) Send(msg *Message) error {
+	data, err := Format(m.From, msg)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		host, _, err := net.SplitHostPort(m.Addr)
+		if err != nil {
+			return err
+		}
+
+		auth = smtp.PlainAuth("", m.Username, m.Password, host)
+	}
+
+	return smtp.SendMail(m.Addr, auth, m.From, []string{msg.To}, data)
+}
+
+// Format returns the message encoded with headers for delivery.
+func Format(from string, msg *Message) ([]byte, error) {
+	if strings.ContainsAny(from+msg.To, "\r\n") {
+		return nil, errHeader
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+
+	body := strings.ReplaceAll(msg.Body, "\r\n", "\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+
+	return b.Bytes(), nil
+}
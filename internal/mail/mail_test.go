@@ -0,0 +1,196 @@
+package mail
+
+import (
+	"bufio"
+	"net"
+	"net/textproto"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	msg := Message{
+		To:      "bob@example.com",
+		Subject: "Größe [alice/repo]",
+		Body:    "first line\nsecond line\r\nthird line",
+	}
+
+	data, err := Format("multiverse@example.com", &msg)
+	if err != nil {
+		t.Fatalf("failed to format message: %v", err)
+	}
+
+	header, body := splitMessage(t, string(data))
+	if header.Get("From") != "multiverse@example.com" {
+		t.Errorf("From = %q", header.Get("From"))
+	}
+
+	if header.Get("To") != "bob@example.com" {
+		t.Errorf("To = %q", header.Get("To"))
+	}
+
+	if header.Get("Subject") != "=?utf-8?q?Gr=C3=B6=C3=9Fe_[alice/repo]?=" {
+		t.Errorf("Subject = %q", header.Get("Subject"))
+	}
+
+	if header.Get("Content-Type") != "text/plain; charset=utf-8" {
+		t.Errorf("Content-Type = %q", header.Get("Content-Type"))
+	}
+
+	if body != "first line\r\nsecond line\r\nthird line\r\n" {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestFormatHeaderInjection(t *testing.T) {
+	tests := []struct {
+		from string
+		to   string
+	}{
+		{"multiverse@example.com", "bob@example.com\r\nBcc: eve@example.com"},
+		{"multiverse@example.com", "bob@example.com\nBcc: eve@example.com"},
+		{"multiverse@example.com\r\nBcc: eve@example.com", "bob@example.com"},
+	}
+
+	for _, test := range tests {
+		if _, err := Format(test.from, &Message{To: test.to}); err != errHeader {
+			t.Errorf("Format(%q, %q) error = %v, want %v", test.from, test.to, err, errHeader)
+		}
+	}
+}
+
+func TestSMTPSend(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer ln.Close()
+
+	received := make(chan smtpMessage, 1)
+	go serveSMTP(t, ln, received)
+
+	mailer := SMTP{Addr: ln.Addr().String(), From: "multiverse@example.com"}
+	msg := Message{To: "bob@example.com", Subject: "Hello", Body: "hello bob\n.hidden dot"}
+
+	if err := mailer.Send(&msg); err != nil {
+		t.Fatalf("failed to send message: %v", err)
+	}
+
+	got := <-received
+	if got.from != "<multiverse@example.com>" {
+		t.Errorf("MAIL FROM = %q", got.from)
+	}
+
+	if len(got.to) != 1 || got.to[0] != "<bob@example.com>" {
+		t.Errorf("RCPT TO = %q", got.to)
+	}
+
+	header, body := splitMessage(t, got.data)
+	if header.Get("Subject") != "Hello" {
+		t.Errorf("Subject = %q", header.Get("Subject"))
+	}
+
+	if body != "hello bob\r\n.hidden dot\r\n" {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestBaseURLFromEnv(t *testing.T) {
+	tests := []struct {
+		addr string
+		base string
+		want string
+		err  error
+	}{
+		{"", "", "", nil},
+		{"localhost:1025", "", "", errNoBase},
+		{"", "https://multiverse.example.com/", "https://multiverse.example.com", nil},
+		{"localhost:1025", "http://localhost:2020", "http://localhost:2020", nil},
+		{"", "multiverse.example.com", "", errBaseURL},
+		{"", "ftp://multiverse.example.com", "", errBaseURL},
+		{"", "https://multiverse.example.com/?next=evil", "", errBaseURL},
+	}
+
+	defer os.Unsetenv(AddrEnv)
+	defer os.Unsetenv(BaseURLEnv)
+
+	for _, test := range tests {
+		os.Setenv(AddrEnv, test.addr)
+		os.Setenv(BaseURLEnv, test.base)
+
+		base, err := BaseURLFromEnv()
+		if base != test.want || err != test.err {
+			t.Errorf("BaseURLFromEnv() with %q = %q, %v, want %q, %v", test.base, base, err, test.want, test.err)
+		}
+	}
+}
+
+// splitMessage returns the headers and body of the formatted message.
+func splitMessage(t *testing.T, data string) (textproto.MIMEHeader, string) {
+	r := textproto.NewReader(bufio.NewReader(strings.NewReader(data)))
+
+	header, err := r.ReadMIMEHeader()
+	if err != nil {
+		t.Fatalf("failed to read headers: %v", err)
+	}
+
+	parts := strings.SplitN(data, "\r\n\r\n", 2)
+	return header, parts[1]
+}
+
+type smtpMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+// serveSMTP accepts a single connection and receives one message over it.
+func serveSMTP(t *testing.T, ln net.Listener, received chan<- smtpMessage) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var msg smtpMessage
+	text := textproto.NewConn(conn)
+	text.PrintfLine("220 localhost ESMTP")
+
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO", "HELO":
+			text.PrintfLine("250 localhost")
+		case "MAIL":
+			msg.from = strings.TrimPrefix(line, "MAIL FROM:")
+			text.PrintfLine("250 OK")
+		case "RCPT":
+			msg.to = append(msg.to, strings.TrimPrefix(line, "RCPT TO:"))
+			text.PrintfLine("250 OK")
+		case "DATA":
+			text.PrintfLine("354 Go ahead")
+
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				t.Errorf("failed to read data: %v", err)
+				return
+			}
+
+			// ReadDotBytes removes dot stuffing and uses bare line feeds
+			msg.data = strings.ReplaceAll(string(data), "\n", "\r\n")
+			text.PrintfLine("250 OK")
+		case "QUIT":
+			text.PrintfLine("221 Bye")
+			received <- msg
+			return
+		default:
+			text.PrintfLine("502 Not implemented")
+		}
+	}
+}
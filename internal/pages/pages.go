@@ -0,0 +1,142 @@
+package pages
+
+import (
+	"context"
+
+	cid "github.com/ipfs/go-cid"
+	pin "github.com/ipfs/go-ipfs-pinner"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/core"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+	"github.com/multiverse-vcs/go-git-ipfs/internal/gitutil"
+)
+
+// Publish materializes the trees of all repository branches and tags
+// into unixfs directories and records their CIDs as snapshots.
+//
+// Callers must hold the pin lock.
+func Publish(ctx context.Context, server *core.Server, repo *database.Repo) error {
+	git, err := server.OpenRepo(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	branches, err := gitutil.Branches(git)
+	if err != nil {
+		return err
+	}
+
+	tags, err := gitutil.Tags(git)
+	if err != nil {
+		return err
+	}
+
+	var snapshots []database.Snapshot
+	if err := server.DB.Find(&snapshots, "repo_id = ?", repo.ID).Error; err != nil {
+		return err
+	}
+
+	stale := make(map[string]database.Snapshot)
+	for _, snap := range snapshots {
+		stale[snap.Name] = snap
+	}
+
+	materializer := gitutil.NewMaterializer(ctx, server.Node.DAG, git)
+	for _, ref := range append(branches, tags...) {
+		name := ref.Name().String()
+
+		snap, ok := stale[name]
+		delete(stale, name)
+
+		commit, err := gitutil.Commit(git, ref)
+		if err != nil {
+			return err
+		}
+
+		if ok && snap.Hash == commit.Hash.String() {
+			continue
+		}
+
+		node, err := materializer.Commit(commit)
+		if err != nil {
+			return err
+		}
+
+		if err := server.Node.Pinning.Pin(ctx, node, true); err != nil {
+			return err
+		}
+
+		old := snap.CID
+
+		snap.RepoID = repo.ID
+		snap.Name = name
+		snap.Hash = commit.Hash.String()
+		snap.CID = node.Cid().String()
+
+		if err := snap.Save(server.DB); err != nil {
+			return err
+		}
+
+		if err := unpin(ctx, server, old); err != nil {
+			return err
+		}
+	}
+
+	for _, snap := range stale {
+		if err := snap.Delete(server.DB); err != nil {
+			return err
+		}
+
+		if err := unpin(ctx, server, snap.CID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Unpublish removes all snapshots of the repository and unpins their directories.
+//
+// Callers must hold the pin lock.
+func Unpublish(ctx context.Context, server *core.Server, repo *database.Repo) error {
+	var snapshots []database.Snapshot
+	if err := server.DB.Find(&snapshots, "repo_id = ?", repo.ID).Error; err != nil {
+		return err
+	}
+
+	for _, snap := range snapshots {
+		if err := snap.Delete(server.DB); err != nil {
+			return err
+		}
+
+		if err := unpin(ctx, server, snap.CID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unpin removes the pin for the given CID if no snapshots reference it.
+func unpin(ctx context.Context, server *core.Server, id string) error {
+	if id == "" {
+		return nil
+	}
+
+	count, err := database.CountSnapshotsByCID(server.DB, id)
+	if err != nil || count > 0 {
+		return err
+	}
+
+	c, err := cid.Decode(id)
+	if err != nil {
+		return err
+	}
+
+	_, pinned, err := server.Node.Pinning.IsPinnedWithType(ctx, c, pin.Recursive)
+	if err != nil || !pinned {
+		return err
+	}
+
+	return server.Node.Pinning.Unpin(ctx, c, true)
+}
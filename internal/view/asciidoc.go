@@ -0,0 +1,233 @@
+package view
+
+import (
+	"regexp"
+	"strings"
+)
+
+// adocMaxDepth is the deepest nesting of delimited blocks that is converted.
+//
+// Deeper blocks are written as literal text so that documents with many
+// nested delimiters cannot make the conversion take quadratic time.
+const adocMaxDepth = 8
+
+var (
+	adocHeading    = regexp.MustCompile(`^(={1,6})\s+(.*)$`)
+	adocAttribute  = regexp.MustCompile(`^:([\w-]+)!?:\s*(.*)$`)
+	adocBlockAttrs = regexp.MustCompile(`^\[\[?([^\]]*)\]\]?$`)
+	adocBlockTitle = regexp.MustCompile(`^\.([^.\s].*)$`)
+	adocImage      = regexp.MustCompile(`^image::([^\[]+)\[([^\],]*)[^\]]*\]$`)
+	adocAdmonition = regexp.MustCompile(`^(NOTE|TIP|IMPORTANT|WARNING|CAUTION):\s+(.*)$`)
+	adocUnordered  = regexp.MustCompile(`^(\*+|-)\s+(.*)$`)
+	adocOrdered    = regexp.MustCompile(`^(\.+)\s+(.*)$`)
+	adocDelimiter  = regexp.MustCompile(`^(-{4,}|\.{4,}|_{4,}|\*{4,}|/{4,}|={4,}|\+{4,})$`)
+	adocReference  = regexp.MustCompile(`\{([\w-]+)\}`)
+	adocLinkMacro  = regexp.MustCompile(`link:([^\[\s]+)\[([^\]]*)\]`)
+	adocURL        = regexp.MustCompile(`\b((?:https?|ftp|mailto):[^\s\[]+)\[([^\]]*)\]`)
+	adocInlineImg  = regexp.MustCompile(`image:([^\[\s:][^\[\s]*)\[([^\],]*)[^\]]*\]`)
+	adocXref       = regexp.MustCompile(`<<([^,>]+)(?:,\s*([^>]+))?>>`)
+	adocBold       = regexp.MustCompile(`(^|[^\w*])\*([^*\s](?:[^*]*[^*\s])?)\*($|[^\w*])`)
+)
+
+// asciidocToMarkdown converts the commonly used subset of AsciiDoc into markdown.
+//
+// Section titles, delimited blocks, source listings, images,
+// admonitions, lists, attribute references and links are supported.
+func asciidocToMarkdown(source []byte) []byte {
+	return []byte(adocConvert(splitLines(source), make(map[string]string), 0))
+}
+
+// adocConvert converts the given lines nested in depth delimited blocks
+// using the document attributes.
+func adocConvert(lines []string, attrs map[string]string, depth int) string {
+	var out strings.Builder
+	var blockAttrs string
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " ")
+
+		switch {
+		case strings.HasPrefix(line, "//") && !strings.HasPrefix(line, "////"):
+			// line comments are not rendered
+		case adocAttribute.MatchString(line):
+			match := adocAttribute.FindStringSubmatch(line)
+			attrs[match[1]] = match[2]
+		case adocBlockAttrs.MatchString(line):
+			blockAttrs = adocBlockAttrs.FindStringSubmatch(line)[1]
+			continue
+		case line == "|===":
+			end := i + 1
+			for end < len(lines) && strings.TrimRight(lines[end], " ") != line {
+				end++
+			}
+
+			header, rows := adocTable(lines[i+1:end], strings.Contains(blockAttrs, "header"))
+			writeTable(&out, header, rows, func(text string) string {
+				return adocInline(text, attrs)
+			})
+			i = end
+		case adocDelimiter.MatchString(line):
+			end := i + 1
+			for end < len(lines) && strings.TrimRight(lines[end], " ") != line {
+				end++
+			}
+
+			adocBlock(&out, line, blockAttrs, attrs, lines[i+1:end], depth+1)
+			i = end
+		case adocHeading.MatchString(line):
+			match := adocHeading.FindStringSubmatch(line)
+			writeHeading(&out, len(match[1]), adocInline(match[2], attrs))
+
+			// the author and revision lines follow the document title
+			for len(match[1]) == 1 && i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" && !adocAttribute.MatchString(lines[i+1]) {
+				i++
+			}
+		case adocImage.MatchString(line):
+			match := adocImage.FindStringSubmatch(line)
+			out.WriteString("![" + match[2] + "](" + adocSubstitute(match[1], attrs) + ")\n\n")
+		case adocAdmonition.MatchString(line):
+			match := adocAdmonition.FindStringSubmatch(line)
+			writeQuote(&out, strings.Title(strings.ToLower(match[1])), adocInline(match[2], attrs))
+		case adocUnordered.MatchString(line):
+			match := adocUnordered.FindStringSubmatch(line)
+			indent := strings.Repeat("  ", len(match[1])-1)
+			out.WriteString(indent + "- " + adocInline(match[2], attrs) + "\n")
+		case adocOrdered.MatchString(line):
+			match := adocOrdered.FindStringSubmatch(line)
+			indent := strings.Repeat("   ", len(match[1])-1)
+			out.WriteString(indent + "1. " + adocInline(match[2], attrs) + "\n")
+		case adocBlockTitle.MatchString(line):
+			out.WriteString("**" + adocInline(adocBlockTitle.FindStringSubmatch(line)[1], attrs) + "**\n\n")
+		default:
+			out.WriteString(adocInline(line, attrs) + "\n")
+		}
+
+		blockAttrs = ""
+	}
+
+	return out.String()
+}
+
+// adocBlock writes the delimited block with the given delimiter.
+func adocBlock(out *strings.Builder, delim, blockAttrs string, attrs map[string]string, lines []string, depth int) {
+	style := strings.Split(blockAttrs, ",")
+	if depth > adocMaxDepth {
+		writeFence(out, "", lines)
+		return
+	}
+
+	switch delim[0] {
+	case '-', '.':
+		lang := ""
+		if len(style) > 1 && style[0] == "source" {
+			lang = style[1]
+		}
+
+		writeFence(out, lang, lines)
+	case '_':
+		writeQuote(out, "", strings.TrimSpace(adocConvert(lines, attrs, depth)))
+	case '+':
+		out.WriteString(strings.Join(lines, "\n") + "\n\n")
+	case '/':
+		// comment blocks are not rendered
+	default:
+		title := ""
+		switch style[0] {
+		case "NOTE", "TIP", "IMPORTANT", "WARNING", "CAUTION":
+			title = strings.Title(strings.ToLower(style[0]))
+		}
+
+		inner := adocConvert(lines, attrs, depth)
+		if title == "" {
+			out.WriteString(inner + "\n")
+		} else {
+			writeQuote(out, title, strings.TrimSpace(inner))
+		}
+	}
+}
+
+// adocTable returns the header and body rows of a table.
+//
+// The first row is the header if it is followed by a blank line or
+// the header option is set. Rows may be written on one line or with
+// one cell per line, in which case the first row sets the column count.
+func adocTable(lines []string, headerOption bool) ([]string, [][]string) {
+	var cells []string
+	var cols int
+	var header []string
+
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "|") {
+			if line != "" && len(cells) > 0 {
+				cells[len(cells)-1] = joinCell(cells[len(cells)-1], line)
+			}
+
+			continue
+		}
+
+		row := strings.Split(line[1:], "|")
+		for j := range row {
+			row[j] = strings.TrimSpace(row[j])
+		}
+
+		if cols == 0 {
+			cols = len(row)
+			blank := i+1 < len(lines) && strings.TrimSpace(lines[i+1]) == ""
+			if blank || headerOption {
+				header = row
+				continue
+			}
+		}
+
+		cells = append(cells, row...)
+	}
+
+	var rows [][]string
+	for cols > 0 && len(cells) > 0 {
+		n := cols
+		if n > len(cells) {
+			n = len(cells)
+		}
+
+		rows = append(rows, cells[:n])
+		cells = cells[n:]
+	}
+
+	return header, rows
+}
+
+// adocInline converts inline markup to markdown.
+func adocInline(text string, attrs map[string]string) string {
+	text = adocSubstitute(text, attrs)
+	text = adocInlineImg.ReplaceAllString(text, "![$2]($1)")
+	text = adocLinkMacro.ReplaceAllString(text, "[$2]($1)")
+	text = adocURL.ReplaceAllStringFunc(text, func(match string) string {
+		parts := adocURL.FindStringSubmatch(match)
+		if parts[2] == "" {
+			return "<" + parts[1] + ">"
+		}
+
+		return "[" + parts[2] + "](" + parts[1] + ")"
+	})
+	text = adocXref.ReplaceAllStringFunc(text, func(match string) string {
+		parts := adocXref.FindStringSubmatch(match)
+		if parts[2] == "" {
+			return "[" + parts[1] + "](#" + parts[1] + ")"
+		}
+
+		return "[" + parts[2] + "](#" + parts[1] + ")"
+	})
+	return adocBold.ReplaceAllString(text, "$1**$2**$3")
+}
+
+// adocSubstitute replaces attribute references with their values.
+func adocSubstitute(text string, attrs map[string]string) string {
+	return adocReference.ReplaceAllStringFunc(text, func(match string) string {
+		if value, ok := attrs[match[1:len(match)-1]]; ok {
+			return value
+		}
+
+		return match
+	})
+}
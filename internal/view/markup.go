@@ -0,0 +1,186 @@
+package view
+
+import (
+	"bytes"
+	"errors"
+	"html"
+	"html/template"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/niklasfasching/go-org/org"
+	xhtml "golang.org/x/net/html"
+)
+
+// renderer converts a markup document into HTML.
+type renderer func(name string, source []byte) (string, error)
+
+// renderers maps file extensions to markup renderers.
+var renderers = map[string]renderer{
+	".md":       renderMarkdown,
+	".markdown": renderMarkdown,
+	".mdown":    renderMarkdown,
+	".mkd":      renderMarkdown,
+	".org":      renderOrg,
+	".rst":      renderRST,
+	".rest":     renderRST,
+	".adoc":     renderAsciiDoc,
+	".asciidoc": renderAsciiDoc,
+	".asc":      renderAsciiDoc,
+}
+
+// markup renders the given reader into HTML using a renderer chosen by the file name.
+//
+// The output is sanitized and relative links and images are resolved against
+// the tree and raw endpoints of the repository at base for the given ref and dir.
+func markup(name, base, ref, dir string, r io.Reader) (template.HTML, error) {
+	source, err := readAll(r)
+	if err != nil {
+		return "", err
+	}
+
+	render, ok := renderers[strings.ToLower(path.Ext(name))]
+	if !ok {
+		render = renderText
+	}
+
+	result, err := render(name, source)
+	if err != nil {
+		return "", err
+	}
+
+	result, err = resolveLinks(sanitize(result), base, ref, dir)
+	if err != nil {
+		return "", err
+	}
+
+	return template.HTML(result), nil
+}
+
+// renderMarkdown renders markdown using goldmark.
+func renderMarkdown(name string, source []byte) (string, error) {
+	var result strings.Builder
+	if err := goldmarkdown.Convert(source, &result); err != nil {
+		return "", err
+	}
+
+	return result.String(), nil
+}
+
+// renderOrg renders org mode documents.
+func renderOrg(name string, source []byte) (string, error) {
+	config := org.New().Silent()
+	config.DefaultSettings["OPTIONS"] = "toc:nil <:t e:t f:t pri:t todo:t tags:t title:t"
+	// include directives must not read from the server filesystem
+	config.ReadFile = func(string) ([]byte, error) {
+		return nil, errors.New("include is not supported")
+	}
+
+	doc := config.Parse(bytes.NewReader(source), name)
+	return doc.Write(org.NewHTMLWriter())
+}
+
+// renderRST renders reStructuredText by converting it to markdown.
+func renderRST(name string, source []byte) (string, error) {
+	return renderMarkdown(name, rstToMarkdown(source))
+}
+
+// renderAsciiDoc renders AsciiDoc by converting it to markdown.
+func renderAsciiDoc(name string, source []byte) (string, error) {
+	return renderMarkdown(name, asciidocToMarkdown(source))
+}
+
+// renderText renders plain text as preformatted text.
+func renderText(name string, source []byte) (string, error) {
+	return "<pre>" + html.EscapeString(string(source)) + "</pre>", nil
+}
+
+// linkAttrs maps element names to attributes containing links.
+var linkAttrs = map[string]string{
+	"a":      "href",
+	"img":    "src",
+	"source": "src",
+	"video":  "src",
+	"audio":  "src",
+}
+
+// resolveLinks rewrites relative links in the given HTML so that they
+// point to the repository tree and images point to the raw endpoint.
+func resolveLinks(source, base, ref, dir string) (string, error) {
+	var result strings.Builder
+
+	tokenizer := xhtml.NewTokenizer(strings.NewReader(source))
+	for {
+		tt := tokenizer.Next()
+		if tt == xhtml.ErrorToken {
+			break
+		}
+
+		raw := tokenizer.Raw()
+		if tt != xhtml.StartTagToken && tt != xhtml.SelfClosingTagToken {
+			result.Write(raw)
+			continue
+		}
+
+		token := tokenizer.Token()
+		if resolveToken(&token, base, ref, dir) {
+			result.WriteString(token.String())
+		} else {
+			result.Write(raw)
+		}
+	}
+
+	if err := tokenizer.Err(); err != io.EOF {
+		return "", err
+	}
+
+	return result.String(), nil
+}
+
+// resolveToken resolves the link attribute of the token and reports whether it changed.
+func resolveToken(token *xhtml.Token, base, ref, dir string) bool {
+	key, ok := linkAttrs[token.Data]
+	if !ok {
+		return false
+	}
+
+	endpoint := "raw"
+	if token.Data == "a" {
+		endpoint = "tree"
+	}
+
+	for i, attr := range token.Attr {
+		if attr.Key != key {
+			continue
+		}
+
+		link, ok := resolveLink(attr.Val, path.Join(base, endpoint, ref), dir)
+		if !ok {
+			return false
+		}
+
+		token.Attr[i].Val = link
+		return true
+	}
+
+	return false
+}
+
+// resolveLink resolves a relative link against the root and dir paths.
+func resolveLink(link, root, dir string) (string, bool) {
+	u, err := url.Parse(link)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" {
+		return "", false
+	}
+
+	fpath := u.Path
+	if !strings.HasPrefix(fpath, "/") {
+		fpath = path.Join("/", dir, fpath)
+	}
+
+	// cleaning the path first keeps links from escaping the ref
+	u.Path = path.Join(root, path.Clean(fpath))
+	return u.String(), true
+}
@@ -0,0 +1,70 @@
+package view
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// converters maps test file extensions to markdown converters.
+var converters = map[string]func([]byte) []byte{
+	".rst":  rstToMarkdown,
+	".adoc": asciidocToMarkdown,
+}
+
+func TestConvertGolden(t *testing.T) {
+	for ext, convert := range converters {
+		inputs, err := filepath.Glob(filepath.Join("testdata", "*"+ext))
+		if err != nil {
+			t.Fatalf("failed to list inputs: %v", err)
+		}
+
+		for _, input := range inputs {
+			source, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatalf("failed to read input: %v", err)
+			}
+
+			golden := strings.TrimSuffix(input, ext) + ext + ".md"
+			result := convert(source)
+
+			if *update {
+				if err := os.WriteFile(golden, result, 0644); err != nil {
+					t.Fatalf("failed to write golden file: %v", err)
+				}
+			}
+
+			expect, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+
+			if string(result) != string(expect) {
+				t.Errorf("%s: unexpected output\n%s\nwant\n%s", input, result, expect)
+			}
+		}
+	}
+}
+
+func TestAsciiDocNestingDepth(t *testing.T) {
+	var lines []string
+	for i := 0; i < 2000; i++ {
+		lines = append(lines, strings.Repeat("=", 4+i))
+	}
+
+	start := time.Now()
+	result := asciidocToMarkdown([]byte(strings.Join(lines, "\n")))
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("conversion took %s", elapsed)
+	}
+
+	if !strings.Contains(string(result), "```\n"+strings.Repeat("=", 4+adocMaxDepth)) {
+		t.Errorf("expected blocks past the maximum depth to be literal")
+	}
+}
@@ -0,0 +1,448 @@
+package view
+
+import (
+	"regexp"
+	"strings"
+)
+
+// rstAdornments contains the characters allowed in section adornments.
+const rstAdornments = "=-~^\"'`#*+:._"
+
+var (
+	rstDirective  = regexp.MustCompile(`^\.\.\s+([a-zA-Z-]+)::\s*(.*)$`)
+	rstEnumerated = regexp.MustCompile(`^(\s*)#\.\s`)
+	rstLiteral    = regexp.MustCompile("``([^`]+)``")
+	rstLink       = regexp.MustCompile("`([^`<]+?)\\s*<([^>`]+)>`__?")
+	rstRole       = regexp.MustCompile(":[a-zA-Z-]+:`([^`]+)`")
+	rstReference  = regexp.MustCompile("`([^`]+)`__?")
+)
+
+// rstAdmonitions contains the directives rendered as block quotes.
+var rstAdmonitions = map[string]bool{
+	"admonition": true,
+	"attention":  true,
+	"caution":    true,
+	"danger":     true,
+	"error":      true,
+	"hint":       true,
+	"important":  true,
+	"note":       true,
+	"tip":        true,
+	"warning":    true,
+}
+
+// rstToMarkdown converts the commonly used subset of reStructuredText into markdown.
+//
+// Section titles, literal and code blocks, images, admonitions,
+// lists, inline literals and hyperlinks are supported.
+func rstToMarkdown(source []byte) []byte {
+	lines := splitLines(source)
+	levels := make(map[string]int)
+
+	var out strings.Builder
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		text := strings.TrimSpace(line)
+
+		switch {
+		case isGridBorder(line):
+			n := 1
+			for n < len(lines)-i && strings.TrimSpace(lines[i+n]) != "" {
+				n++
+			}
+
+			header, rows := gridTable(lines[i : i+n])
+			writeTable(&out, header, rows, rstInline)
+			i += n - 1
+		case len(simpleColumns(line)) > 1:
+			n := simpleTableEnd(lines[i:])
+			header, rows := simpleTable(lines[i : i+n])
+			writeTable(&out, header, rows, rstInline)
+			i += n - 1
+		case i+2 < len(lines) && isAdornment(line) && isAdornment(lines[i+2]) && strings.TrimSpace(lines[i+1]) != "":
+			writeHeading(&out, rstLevel(levels, "over"+line[:1]), rstInline(strings.TrimSpace(lines[i+1])))
+			i += 2
+		case i+1 < len(lines) && text != "" && line[0] != ' ' && isAdornment(lines[i+1]) && len(strings.TrimSpace(lines[i+1])) >= len(text):
+			writeHeading(&out, rstLevel(levels, lines[i+1][:1]), rstInline(text))
+			i++
+		case rstDirective.MatchString(line):
+			match := rstDirective.FindStringSubmatch(line)
+			body, n := indentedBlock(lines[i+1:])
+			i += n
+
+			name := strings.ToLower(match[1])
+			switch {
+			case name == "code" || name == "code-block" || name == "sourcecode":
+				writeFence(&out, match[2], dropOptions(body))
+			case name == "image" || name == "figure":
+				out.WriteString("![](" + match[2] + ")\n\n")
+			case rstAdmonitions[name]:
+				title := strings.Title(name)
+				if name == "admonition" {
+					title = match[2]
+				} else if match[2] != "" {
+					body = append([]string{match[2]}, body...)
+				}
+
+				writeQuote(&out, title, rstInline(strings.Join(dropOptions(body), "\n")))
+			}
+		case strings.HasPrefix(line, ".."):
+			// comments and hyperlink targets are not rendered
+			_, n := indentedBlock(lines[i+1:])
+			i += n
+		case strings.HasSuffix(text, "::"):
+			text = strings.TrimSuffix(text, "::")
+			if text != "" && !strings.HasSuffix(text, " ") {
+				text += ":"
+			}
+
+			out.WriteString(rstInline(strings.TrimSpace(text)) + "\n\n")
+
+			body, n := indentedBlock(lines[i+1:])
+			writeFence(&out, "", body)
+			i += n
+		default:
+			out.WriteString(rstEnumerated.ReplaceAllString(rstInline(line), "${1}1. ") + "\n")
+		}
+	}
+
+	return []byte(out.String())
+}
+
+// rstInline converts inline markup to markdown.
+func rstInline(text string) string {
+	text = rstLink.ReplaceAllString(text, "[$1]($2)")
+	text = rstLiteral.ReplaceAllString(text, "`$1`")
+	text = rstRole.ReplaceAllString(text, "`$1`")
+	return rstReference.ReplaceAllString(text, "$1")
+}
+
+// rstLevel returns the heading level for the given adornment style.
+//
+// Levels are assigned in the order styles are first encountered.
+func rstLevel(levels map[string]int, style string) int {
+	if level, ok := levels[style]; ok {
+		return level
+	}
+
+	level := len(levels) + 1
+	if level > 6 {
+		level = 6
+	}
+
+	levels[style] = level
+	return level
+}
+
+// isAdornment returns true if the line is a section adornment.
+func isAdornment(line string) bool {
+	line = strings.TrimRight(line, " ")
+	if len(line) < 2 || !strings.ContainsRune(rstAdornments, rune(line[0])) {
+		return false
+	}
+
+	return strings.Count(line, line[:1]) == len(line)
+}
+
+// isGridBorder returns true if the line is a border of a grid table.
+func isGridBorder(line string) bool {
+	line = strings.TrimSpace(line)
+	if len(line) < 3 || line[0] != '+' || line[len(line)-1] != '+' || !strings.ContainsAny(line, "-=") {
+		return false
+	}
+
+	return strings.Trim(line, "+-=") == ""
+}
+
+// gridTable returns the header and body rows of a grid table.
+//
+// Cell contents spanning several lines are joined with spaces.
+func gridTable(lines []string) ([]string, [][]string) {
+	var bounds []int
+	for i, c := range lines[0] {
+		if c == '+' {
+			bounds = append(bounds, i)
+		}
+	}
+
+	var header []string
+	var rows [][]string
+	var row []string
+
+	for _, line := range lines[1:] {
+		if isGridBorder(line) {
+			if row != nil && strings.Contains(line, "=") && rows == nil && header == nil {
+				header = row
+			} else if row != nil {
+				rows = append(rows, row)
+			}
+
+			row = nil
+			continue
+		}
+
+		if row == nil {
+			row = make([]string, len(bounds)-1)
+		}
+
+		for j := range row {
+			row[j] = joinCell(row[j], slice(line, bounds[j]+1, bounds[j+1]))
+		}
+	}
+
+	if row != nil {
+		rows = append(rows, row)
+	}
+
+	return header, rows
+}
+
+// simpleColumns returns the column start and end offsets of a simple table border.
+func simpleColumns(line string) [][2]int {
+	line = strings.TrimRight(line, " ")
+	if line == "" || line[0] != '=' || strings.Trim(line, "= ") != "" {
+		return nil
+	}
+
+	var cols [][2]int
+	for i := 0; i < len(line); {
+		start := i
+		for i < len(line) && line[i] == '=' {
+			i++
+		}
+
+		cols = append(cols, [2]int{start, i})
+		for i < len(line) && line[i] == ' ' {
+			i++
+		}
+	}
+
+	return cols
+}
+
+// simpleTableEnd returns the number of lines in the simple table
+// that starts at the first line, which is its top border.
+func simpleTableEnd(lines []string) int {
+	for n := 1; n < len(lines); n++ {
+		if simpleColumns(lines[n]) == nil {
+			continue
+		}
+
+		if n+1 == len(lines) || strings.TrimSpace(lines[n+1]) == "" {
+			return n + 1
+		}
+	}
+
+	n := 1
+	for n < len(lines) && strings.TrimSpace(lines[n]) != "" {
+		n++
+	}
+
+	return n
+}
+
+// simpleTable returns the header and body rows of a simple table.
+//
+// Rows above a border inside the table form the header and
+// rows with an empty first column continue the previous row.
+func simpleTable(lines []string) ([]string, [][]string) {
+	cols := simpleColumns(lines[0])
+
+	var header []string
+	var rows [][]string
+
+	for i, line := range lines[1:] {
+		if simpleColumns(line) != nil {
+			if header == nil && len(rows) > 0 && i+2 < len(lines) {
+				header = rows[0]
+				for _, row := range rows[1:] {
+					for j := range header {
+						header[j] = joinCell(header[j], row[j])
+					}
+				}
+
+				rows = nil
+			}
+
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		cells := make([]string, len(cols))
+		for j, col := range cols {
+			end := len(line)
+			if j+1 < len(cols) {
+				end = cols[j+1][0]
+			}
+
+			cells[j] = strings.TrimSpace(slice(line, col[0], end))
+		}
+
+		if cells[0] == "" && len(rows) > 0 {
+			prev := rows[len(rows)-1]
+			for j := range prev {
+				prev[j] = joinCell(prev[j], cells[j])
+			}
+
+			continue
+		}
+
+		rows = append(rows, cells)
+	}
+
+	return header, rows
+}
+
+// slice returns the part of the line between the offsets, clamped to its length.
+func slice(line string, start, end int) string {
+	if start > len(line) {
+		return ""
+	}
+
+	if end > len(line) {
+		end = len(line)
+	}
+
+	return line[start:end]
+}
+
+// joinCell appends a line of text to the contents of a table cell.
+func joinCell(cell, text string) string {
+	text = strings.TrimSpace(text)
+	if cell == "" || text == "" {
+		return cell + text
+	}
+
+	return cell + " " + text
+}
+
+// dropOptions removes directive options from the start of a block.
+func dropOptions(lines []string) []string {
+	for len(lines) > 0 && strings.HasPrefix(lines[0], ":") {
+		lines = lines[1:]
+	}
+
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+
+	return lines
+}
+
+// indentedBlock returns the dedented block of indented lines at the start of
+// the given lines and the number of lines consumed.
+func indentedBlock(lines []string) ([]string, int) {
+	n := 0
+	for n < len(lines) && (strings.TrimSpace(lines[n]) == "" || lines[n][0] == ' ' || lines[n][0] == '\t') {
+		n++
+	}
+
+	// trailing blank lines separate the block from the next paragraph
+	end := n
+	for end > 0 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+
+	indent := -1
+	for _, line := range lines[:end] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		width := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || width < indent {
+			indent = width
+		}
+	}
+
+	var block []string
+	for _, line := range lines[:end] {
+		if len(line) >= indent && indent > 0 {
+			line = line[indent:]
+		}
+
+		if len(block) == 0 && strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		block = append(block, line)
+	}
+
+	return block, end
+}
+
+// splitLines returns the lines of the source with normalized line endings.
+func splitLines(source []byte) []string {
+	text := strings.ReplaceAll(string(source), "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\t", "    ")
+	return strings.Split(text, "\n")
+}
+
+// writeHeading writes a markdown heading with the given level.
+func writeHeading(out *strings.Builder, level int, text string) {
+	out.WriteString(strings.Repeat("#", level) + " " + text + "\n\n")
+}
+
+// writeFence writes a fenced markdown code block.
+func writeFence(out *strings.Builder, lang string, lines []string) {
+	fence := "```"
+	for _, line := range lines {
+		for strings.HasPrefix(strings.TrimSpace(line), fence) {
+			fence += "`"
+		}
+	}
+
+	out.WriteString(fence + strings.TrimSpace(lang) + "\n")
+	out.WriteString(strings.Join(lines, "\n"))
+	out.WriteString("\n" + fence + "\n\n")
+}
+
+// writeTable writes a markdown table.
+//
+// Markdown tables require a header so an empty one is written when missing.
+func writeTable(out *strings.Builder, header []string, rows [][]string, inline func(string) string) {
+	width := len(header)
+	for _, row := range rows {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+
+	writeRow := func(cells []string) {
+		out.WriteString("|")
+		for i := 0; i < width; i++ {
+			cell := ""
+			if i < len(cells) {
+				cell = strings.ReplaceAll(inline(cells[i]), "|", "\\|")
+			}
+
+			out.WriteString(" " + cell + " |")
+		}
+
+		out.WriteString("\n")
+	}
+
+	writeRow(header)
+	out.WriteString("|" + strings.Repeat(" --- |", width) + "\n")
+	for _, row := range rows {
+		writeRow(row)
+	}
+
+	out.WriteString("\n")
+}
+
+// writeQuote writes a markdown block quote with an optional bold title.
+func writeQuote(out *strings.Builder, title, text string) {
+	if title != "" {
+		text = "**" + title + ":** " + text
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		out.WriteString("> " + line + "\n")
+	}
+
+	out.WriteString("\n")
+}
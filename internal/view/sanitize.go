@@ -0,0 +1,25 @@
+package view
+
+import (
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// policy is the allow-list used to sanitize user generated HTML.
+var policy = newPolicy()
+
+// newPolicy returns a policy allowing user generated content
+// along with the classes used by syntax highlighting.
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(bluemonday.SpaceSeparatedTokens).OnElements("code", "div", "pre", "span", "table", "td", "tr")
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").Matching(regexp.MustCompile(`^(|checked|disabled)$`)).OnElements("input")
+	return p
+}
+
+// sanitize removes all elements and attributes not on the allow-list.
+func sanitize(source string) string {
+	return policy.Sanitize(source)
+}
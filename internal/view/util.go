@@ -14,11 +14,13 @@ import (
 	"github.com/yuin/goldmark-highlighting"
 	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
+	gmhtml "github.com/yuin/goldmark/renderer/html"
 )
 
 const syntaxStyle = "monokai"
 
 var formatter = html.New(
+	html.WithClasses(true),
 	html.WithLineNumbers(true),
 	html.LineNumbersInTable(true),
 	html.LinkableLineNumbers(true, ""),
@@ -29,16 +31,34 @@ var goldmarkdown = goldmark.New(
 		extension.GFM,
 		highlighting.NewHighlighting(
 			highlighting.WithStyle(syntaxStyle),
+			highlighting.WithFormatOptions(
+				html.WithClasses(true),
+			),
 		),
 	),
 	goldmark.WithParserOptions(
 		parser.WithAutoHeadingID(),
 	),
+	goldmark.WithRendererOptions(
+		// raw html is allowed because the output is sanitized
+		gmhtml.WithUnsafe(),
+	),
 )
 
+// readAll reads the reader to the end and closes it if it is an io.Closer.
+//
+// Templates pass readers opened with Blob.Reader, which they cannot close.
+func readAll(r io.Reader) ([]byte, error) {
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	return io.ReadAll(r)
+}
+
 // highlight renders the given reader into highlighted HTML.
 func highlight(name string, r io.Reader) (template.HTML, error) {
-	source, err := io.ReadAll(r)
+	source, err := readAll(r)
 	if err != nil {
 		return "", err
 	}
@@ -68,9 +88,9 @@ func highlight(name string, r io.Reader) (template.HTML, error) {
 	return template.HTML(result.String()), nil
 }
 
-// markdown renders the given reader into HTML.
+// markdown renders the given reader into sanitized HTML.
 func markdown(r io.Reader) (template.HTML, error) {
-	source, err := io.ReadAll(r)
+	source, err := readAll(r)
 	if err != nil {
 		return "", err
 	}
@@ -80,7 +100,19 @@ func markdown(r io.Reader) (template.HTML, error) {
 		return "", err
 	}
 
-	return template.HTML(result.String()), nil
+	return template.HTML(sanitize(result.String())), nil
+}
+
+// markdownString renders the given markdown text into sanitized HTML.
+func markdownString(text string) (template.HTML, error) {
+	return markdown(strings.NewReader(text))
+}
+
+// RenderMarkdown renders the given markdown text into sanitized HTML
+// for use outside of templates.
+func RenderMarkdown(text string) (string, error) {
+	html, err := markdownString(text)
+	return string(html), err
 }
 
 // breadcrumbs returns a list of breadcrumbs for the given URL.
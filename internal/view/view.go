@@ -7,6 +7,7 @@ import (
 	"path"
 	"strings"
 
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
 	"github.com/multiverse-vcs/go-git-ipfs/web"
 )
 
@@ -14,11 +15,15 @@ import (
 var Development = false
 
 var funcs = template.FuncMap{
-	"markdown":    markdown,
-	"highlight":   highlight,
-	"joinURL":     path.Join,
-	"baseURL":     path.Base,
-	"breadcrumbs": breadcrumbs,
+	"markdown":       markdown,
+	"markdownString": markdownString,
+	"markup":         markup,
+	"highlight":      highlight,
+	"joinURL":        path.Join,
+	"baseURL":        path.Base,
+	"breadcrumbs":    breadcrumbs,
+	"hasPrefix":      strings.HasPrefix,
+	"hasRole":        database.HasRole,
 }
 
 var templates = template.Must(template.New("index.html").Funcs(funcs).ParseFS(web.HTML, "html/*.html"))
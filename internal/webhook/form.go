@@ -0,0 +1,43 @@
+package webhook
+
+import (
+	"net/http"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+)
+
+// DeliveryLogSize is the number of deliveries shown per webhook.
+const DeliveryLogSize = 10
+
+// FromForm returns a webhook with the fields of the submitted form.
+//
+// The secret is encrypted with the server key.
+func FromForm(req *http.Request, key []byte) (database.Webhook, error) {
+	req.ParseForm()
+
+	hook := database.Webhook{
+		URL:    strings.TrimSpace(req.FormValue("url")),
+		Secret: req.FormValue("secret"),
+		Events: strings.Join(req.Form["events"], ","),
+	}
+
+	return hook, SealSecret(key, &hook)
+}
+
+// DeliveryLog returns the recent deliveries of each webhook.
+func DeliveryLog(db *gorm.DB, hooks []database.Webhook) (map[uint][]database.Delivery, error) {
+	deliveries := make(map[uint][]database.Delivery)
+	for _, hook := range hooks {
+		list, err := database.FindDeliveriesByWebhookID(db, hook.ID, DeliveryLogSize)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries[hook.ID] = list
+	}
+
+	return deliveries, nil
+}
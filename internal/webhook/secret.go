@@ -0,0 +1,75 @@
+package webhook
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+)
+
+var errCiphertext = errors.New("webhook secret ciphertext is too short")
+
+// SealSecret encrypts the secret of the webhook with a key derived from the
+// server key, so that a copy of the database alone does not reveal secrets.
+func SealSecret(key []byte, hook *database.Webhook) error {
+	if hook.Secret == "" {
+		hook.SecretCiphertext = nil
+		return nil
+	}
+
+	aead, err := secretAEAD(key)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	hook.SecretCiphertext = aead.Seal(nonce, nonce, []byte(hook.Secret), nil)
+	return nil
+}
+
+// OpenSecret decrypts the secret of the webhook.
+func OpenSecret(key []byte, hook *database.Webhook) (string, error) {
+	if len(hook.SecretCiphertext) == 0 {
+		return "", nil
+	}
+
+	aead, err := secretAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	size := aead.NonceSize()
+	if len(hook.SecretCiphertext) < size {
+		return "", errCiphertext
+	}
+
+	nonce, sealed := hook.SecretCiphertext[:size], hook.SecretCiphertext[size:]
+	secret, err := aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(secret), nil
+}
+
+// secretAEAD returns the cipher encrypting webhook secrets.
+// Its key is derived from the server key.
+func secretAEAD(key []byte) (cipher.AEAD, error) {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("webhook/secret"))
+
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,279 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+)
+
+const (
+	// MaxAttempts is the number of times a payload is sent before giving up.
+	MaxAttempts = 5
+	// RetryDelay is the delay before the first retry. It doubles after every attempt.
+	RetryDelay = 5 * time.Second
+	// ResponseLimit is the number of response bytes recorded in the delivery log.
+	ResponseLimit = 1024
+)
+
+const (
+	// EventHeader contains the event type.
+	EventHeader = "X-Multiverse-Event"
+	// DeliveryHeader contains the delivery ID.
+	DeliveryHeader = "X-Multiverse-Delivery"
+	// SignatureHeader contains the hex encoded HMAC-SHA256 of the payload.
+	SignatureHeader = "X-Multiverse-Signature-256"
+)
+
+// AllowPrivateEnv enables payloads to loopback, link-local and private
+// addresses when set to true. It is meant for local development.
+const AllowPrivateEnv = "MULTIVERSE_WEBHOOK_ALLOW_PRIVATE"
+
+// ErrPrivateAddress is returned when a webhook resolves to a non-public address.
+var ErrPrivateAddress = errors.New("webhook address is not public")
+
+// privateNetworks contains the ranges webhooks cannot reach by default
+// in addition to loopback, link-local, multicast and unspecified addresses.
+var privateNetworks = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),
+	mustParseCIDR("10.0.0.0/8"),
+	mustParseCIDR("100.64.0.0/10"),
+	mustParseCIDR("172.16.0.0/12"),
+	mustParseCIDR("192.168.0.0/16"),
+	mustParseCIDR("198.18.0.0/15"),
+	mustParseCIDR("fc00::/7"),
+}
+
+// client is used to send payloads.
+var client = newClient(allowPrivate())
+
+// Repository contains the repository an event occurred on.
+type Repository struct {
+	Owner string `json:"owner"`
+	Name  string `json:"name"`
+	CID   string `json:"cid"`
+}
+
+// Ref contains a ref update.
+//
+// The zero hash is used for created and deleted refs.
+type Ref struct {
+	Name string `json:"name"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+// Payload is the body of a webhook request.
+type Payload struct {
+	Event      string     `json:"event"`
+	Repository Repository `json:"repository"`
+	Pusher     string     `json:"pusher,omitempty"`
+	Refs       []Ref      `json:"refs,omitempty"`
+}
+
+// Trigger sends the payload to all webhooks subscribed to its event.
+//
+// Deliveries are recorded and sent in the background.
+// Secrets are decrypted with the server key.
+func Trigger(db *gorm.DB, key []byte, repo *database.Repo, payload *Payload) error {
+	hooks, err := database.FindWebhooksForEvent(db, repo, payload.Event)
+	if err != nil || len(hooks) == 0 {
+		return err
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range hooks {
+		delivery := database.Delivery{
+			WebhookID: hook.ID,
+			Event:     payload.Event,
+			Payload:   string(data),
+		}
+
+		if err := delivery.Create(db); err != nil {
+			return err
+		}
+
+		go deliver(db, key, hook, delivery)
+	}
+
+	return nil
+}
+
+// Redeliver sends the payload of a previous delivery again.
+func Redeliver(db *gorm.DB, key []byte, hook *database.Webhook, prev *database.Delivery) error {
+	delivery := database.Delivery{
+		WebhookID: hook.ID,
+		Event:     prev.Event,
+		Payload:   prev.Payload,
+	}
+
+	if err := delivery.Create(db); err != nil {
+		return err
+	}
+
+	go deliver(db, key, *hook, delivery)
+	return nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the payload.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliver sends the delivery payload until it succeeds or runs out of attempts.
+func deliver(db *gorm.DB, key []byte, hook database.Webhook, delivery database.Delivery) {
+	delay := RetryDelay
+	for delivery.Attempts < MaxAttempts {
+		if delivery.Attempts > 0 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+
+		delivery.Attempts++
+		delivery.StatusCode, delivery.Response, delivery.Error = 0, "", ""
+
+		if err := send(key, hook, &delivery); err != nil {
+			delivery.Error = err.Error()
+		} else {
+			now := time.Now()
+			delivery.DeliveredAt = &now
+		}
+
+		if err := delivery.Save(db); err != nil {
+			log.Println(err)
+			return
+		}
+
+		if delivery.DeliveredAt != nil {
+			return
+		}
+	}
+}
+
+// send posts the delivery payload to the webhook URL.
+func send(key []byte, hook database.Webhook, delivery *database.Delivery) error {
+	body := []byte(delivery.Payload)
+
+	secret, err := OpenSecret(key, &hook)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "multiverse-webhook")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	if secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(secret, body))
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	response, err := io.ReadAll(io.LimitReader(res.Body, ResponseLimit))
+	if err != nil {
+		return err
+	}
+
+	delivery.StatusCode = res.StatusCode
+	delivery.Response = string(response)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", res.Status)
+	}
+
+	return nil
+}
+
+// newClient returns a client that refuses to connect to non-public
+// addresses unless allowed. Addresses are checked when dialing so that
+// DNS changes and redirects cannot bypass the check.
+func newClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if !allowPrivate {
+		dialer.Control = checkAddress
+	}
+
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+
+	return &http.Client{Transport: transport, Timeout: 10 * time.Second}
+}
+
+// allowPrivate returns true if private addresses are enabled by the environment.
+func allowPrivate() bool {
+	allow, _ := strconv.ParseBool(os.Getenv(AllowPrivateEnv))
+	return allow
+}
+
+// checkAddress returns an error if the dialed address is not public.
+func checkAddress(network, address string, conn syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublic(ip) {
+		return ErrPrivateAddress
+	}
+
+	return nil
+}
+
+// isPublic returns true if the address is reachable on the public internet.
+func isPublic(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+
+	for _, network := range privateNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// mustParseCIDR returns the network of the CIDR notation or panics.
+func mustParseCIDR(s string) *net.IPNet {
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return network
+}
@@ -0,0 +1,162 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/multiverse-vcs/go-git-ipfs/internal/database"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	db, err := database.Open(sqlite.Open("file::memory:"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get database: %v", err)
+	}
+
+	// every connection would open a separate in-memory database
+	sqlDB.SetMaxOpenConns(1)
+
+	db.Logger = logger.Discard
+	return db
+}
+
+func TestIsPublic(t *testing.T) {
+	tests := []struct {
+		ip     string
+		public bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"100.64.0.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"224.0.0.1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+	}
+
+	for _, test := range tests {
+		if public := isPublic(net.ParseIP(test.ip)); public != test.public {
+			t.Errorf("isPublic(%s) = %t, want %t", test.ip, public, test.public)
+		}
+	}
+}
+
+func TestSendRejectsPrivateAddress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		t.Error("request reached a private address")
+	}))
+	defer server.Close()
+
+	hook := database.Webhook{URL: server.URL}
+	delivery := database.Delivery{Event: database.WebhookPushEvent, Payload: "{}"}
+
+	err := send(testKey(t), hook, &delivery)
+	if !errors.Is(err, ErrPrivateAddress) {
+		t.Fatalf("expected private address error, got %v", err)
+	}
+}
+
+func TestSendSignsPayload(t *testing.T) {
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		io.Copy(io.Discard, req.Body)
+		signature = req.Header.Get(SignatureHeader)
+	}))
+	defer server.Close()
+
+	// the test server listens on loopback
+	prev := client
+	client = newClient(true)
+	defer func() { client = prev }()
+
+	key := testKey(t)
+	hook := database.Webhook{URL: server.URL, Secret: "secret"}
+	if err := SealSecret(key, &hook); err != nil {
+		t.Fatalf("failed to seal secret: %v", err)
+	}
+
+	// only the ciphertext is stored
+	hook.Secret = ""
+
+	delivery := database.Delivery{Event: database.WebhookPushEvent, Payload: "{}"}
+	if err := send(key, hook, &delivery); err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+
+	if expect := "sha256=" + Sign("secret", []byte("{}")); signature != expect {
+		t.Fatalf("signature %q, want %q", signature, expect)
+	}
+}
+
+func TestSecretRoundTrip(t *testing.T) {
+	db := openTestDB(t)
+	key := testKey(t)
+
+	hook := database.Webhook{URL: "https://example.com", Events: database.WebhookPushEvent, Secret: "secret"}
+	if err := SealSecret(key, &hook); err != nil {
+		t.Fatalf("failed to seal secret: %v", err)
+	}
+
+	if err := hook.Create(db); err != nil {
+		t.Fatalf("failed to create webhook: %v", err)
+	}
+
+	if bytes.Contains(hook.SecretCiphertext, []byte("secret")) {
+		t.Fatal("ciphertext contains the secret")
+	}
+
+	var stored database.Webhook
+	if err := stored.Find(db, hook.ID); err != nil {
+		t.Fatalf("failed to find webhook: %v", err)
+	}
+
+	if stored.Secret != "" {
+		t.Fatalf("secret was stored in plain text")
+	}
+
+	secret, err := OpenSecret(key, &stored)
+	if err != nil {
+		t.Fatalf("failed to open secret: %v", err)
+	}
+
+	if secret != "secret" {
+		t.Fatalf("secret %q", secret)
+	}
+
+	if _, err := OpenSecret(testKey(t), &stored); err == nil {
+		t.Fatal("secret opened with another key")
+	}
+}
+
+func testKey(t *testing.T) []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	return key
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+
+	"github.com/multiverse-vcs/go-git-ipfs/pkg/storage/unixfs"
+)
+
+// KeySize is the size of repository and key encryption keys.
+const KeySize = 32
+
+var (
+	ErrInvalidKey = errors.New("encryption key must be 32 bytes")
+	ErrDecrypt    = errors.New("failed to decrypt contents")
+)
+
+// EncryptedFS encrypts file contents with AES-256-GCM before they are
+// written to the unixfs directory and decrypts them when read.
+//
+// File paths are used as additional data so that encrypted files cannot
+// be moved to other paths. Paths, and therefore object hashes and ref
+// names, are stored in plaintext.
+type EncryptedFS struct {
+	*unixfs.Unixfs
+	aead cipher.AEAD
+}
+
+// NewEncryptedFS returns a file system encrypting contents with the given key.
+func NewEncryptedFS(fs *unixfs.Unixfs, key []byte) (*EncryptedFS, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EncryptedFS{fs, aead}, nil
+}
+
+// Read returns a reader for the decrypted file at the given path.
+func (e *EncryptedFS) Read(fpath string) (io.ReadCloser, error) {
+	r, err := e.Unixfs.Read(fpath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	sealed, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := open(e.aead, sealed, []byte(fpath))
+	if err != nil {
+		return nil, err
+	}
+
+	return io.NopCloser(bytes.NewReader(plain)), nil
+}
+
+// Write encrypts the contents of the given reader and writes them to the path.
+func (e *EncryptedFS) Write(fpath string, r io.Reader) error {
+	plain, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	sealed, err := seal(e.aead, plain, []byte(fpath))
+	if err != nil {
+		return err
+	}
+
+	return e.Unixfs.Write(fpath, bytes.NewReader(sealed))
+}
+
+// GenerateKey returns a new random key.
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// WrapKey encrypts the key with the key encryption key.
+func WrapKey(kek, key []byte) ([]byte, error) {
+	aead, err := newAEAD(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	return seal(aead, key, nil)
+}
+
+// UnwrapKey decrypts a key wrapped with the key encryption key.
+func UnwrapKey(kek, wrapped []byte) ([]byte, error) {
+	aead, err := newAEAD(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	return open(aead, wrapped, nil)
+}
+
+// newAEAD returns an AES-GCM cipher using the given key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts the plaintext and prepends a random nonce.
+func seal(aead cipher.AEAD, plain, data []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plain, data), nil
+}
+
+// open decrypts ciphertext created by seal.
+func open(aead cipher.AEAD, sealed, data []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+
+	nonce, sealed := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+
+	plain, err := aead.Open(nil, nonce, sealed, data)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	return plain, nil
+}
@@ -0,0 +1,23 @@
+package storage
+
+import (
+	"io"
+
+	ipld "github.com/ipfs/go-ipld-format"
+
+	"github.com/multiverse-vcs/go-git-ipfs/pkg/storage/unixfs"
+)
+
+// FS is the file system used to read and write repository files.
+type FS interface {
+	// Read returns a reader for the file at the given path.
+	Read(fpath string) (io.ReadCloser, error)
+	// Write writes the contents of the given reader to the path.
+	Write(fpath string, r io.Reader) error
+	// Find returns the node at the given path.
+	Find(fpath string) (ipld.Node, error)
+	// Remove removes the node at the given path.
+	Remove(fpath string) error
+	// Walk invokes the callback for each entry of the directory at the given path.
+	Walk(fpath string, cb unixfs.WalkFun) error
+}
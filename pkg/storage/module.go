@@ -7,12 +7,16 @@ import (
 
 type ModuleStorage struct {
 	fs      *unixfs.Unixfs
+	key     []byte
 	modules map[string]*Storage
 }
 
-func NewModuleStorage(fs *unixfs.Unixfs) ModuleStorage {
+// NewModuleStorage returns a module storage. Modules are
+// encrypted with the given key unless it is nil.
+func NewModuleStorage(fs *unixfs.Unixfs, key []byte) ModuleStorage {
 	return ModuleStorage{
 		fs:      fs,
+		key:     key,
 		modules: make(map[string]*Storage),
 	}
 }
@@ -28,6 +32,13 @@ func (s *ModuleStorage) Module(name string) (storage.Storer, error) {
 	}
 
 	module := NewStorage(fs)
+	if s.key != nil {
+		module, err = NewEncryptedStorage(fs, s.key)
+	}
+	if err != nil {
+		return nil, err
+	}
+
 	s.modules[name] = module
 	return module, nil
 }
@@ -19,10 +19,10 @@ import (
 var ErrUnsupportedObjectType = errors.New("unsupported object type")
 
 type ObjectStorage struct {
-	fs *unixfs.Unixfs
+	fs FS
 }
 
-func NewObjectStorage(fs *unixfs.Unixfs) ObjectStorage {
+func NewObjectStorage(fs FS) ObjectStorage {
 	return ObjectStorage{fs}
 }
 
@@ -14,10 +14,10 @@ import (
 )
 
 type ReferenceStorage struct {
-	fs *unixfs.Unixfs
+	fs FS
 }
 
-func NewReferenceStorage(fs *unixfs.Unixfs) ReferenceStorage {
+func NewReferenceStorage(fs FS) ReferenceStorage {
 	return ReferenceStorage{fs}
 }
 
@@ -21,6 +21,23 @@ func NewStorage(fs *unixfs.Unixfs) *Storage {
 		ShallowStorage:   ShallowStorage{},
 		ReferenceStorage: NewReferenceStorage(fs),
 		ObjectStorage:    NewObjectStorage(fs),
-		ModuleStorage:    NewModuleStorage(fs),
+		ModuleStorage:    NewModuleStorage(fs, nil),
 	}
 }
+
+// NewEncryptedStorage returns a storer using a unixfs directory
+// where object and reference contents are encrypted with the key.
+func NewEncryptedStorage(fs *unixfs.Unixfs, key []byte) (*Storage, error) {
+	efs, err := NewEncryptedFS(fs, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Storage{
+		ConfigStorage:    ConfigStorage{},
+		ShallowStorage:   ShallowStorage{},
+		ReferenceStorage: NewReferenceStorage(efs),
+		ObjectStorage:    NewObjectStorage(efs),
+		ModuleStorage:    NewModuleStorage(fs, key),
+	}, nil
+}
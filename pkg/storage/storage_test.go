@@ -2,8 +2,11 @@ package storage
 
 import (
 	"context"
+	"io"
+	"strings"
 	"testing"
 
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/storage/test"
 	"github.com/ipfs/go-merkledag/dagutils"
 	. "gopkg.in/check.v1"
@@ -33,3 +36,77 @@ func (s *StorageSuite) SetUpTest(c *C) {
 	storer := NewStorage(fs)
 	s.BaseStorageSuite = test.NewBaseStorageSuite(storer)
 }
+
+type EncryptedStorageSuite struct {
+	test.BaseStorageSuite
+	fs  *unixfs.Unixfs
+	key []byte
+}
+
+var _ = Suite(&EncryptedStorageSuite{})
+
+func (s *EncryptedStorageSuite) SetUpTest(c *C) {
+	ctx := context.Background()
+	ds := dagutils.NewMemoryDagService()
+
+	fs, err := unixfs.New(ctx, ds)
+	if err != nil {
+		c.Fatalf("failed to create unixfs: %v", err)
+	}
+
+	key, err := GenerateKey()
+	if err != nil {
+		c.Fatalf("failed to generate key: %v", err)
+	}
+
+	storer, err := NewEncryptedStorage(fs, key)
+	if err != nil {
+		c.Fatalf("failed to create storage: %v", err)
+	}
+
+	s.BaseStorageSuite = test.NewBaseStorageSuite(storer)
+	s.fs = fs
+	s.key = key
+}
+
+func (s *EncryptedStorageSuite) TestReferenceCiphertext(c *C) {
+	ref := plumbing.NewHashReference("refs/heads/main", plumbing.NewHash("bc9968d75e48de59f0870ffb71f5e160bbbdcf52"))
+	c.Assert(s.Storer.SetReference(ref), IsNil)
+
+	r, err := s.fs.Read("refs/heads/main")
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	raw, err := io.ReadAll(r)
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(raw), ref.Hash().String()), Equals, false)
+}
+
+func (s *EncryptedStorageSuite) TestWrongKey(c *C) {
+	ref := plumbing.NewHashReference("refs/heads/main", plumbing.NewHash("bc9968d75e48de59f0870ffb71f5e160bbbdcf52"))
+	c.Assert(s.Storer.SetReference(ref), IsNil)
+
+	key, err := GenerateKey()
+	c.Assert(err, IsNil)
+
+	storer, err := NewEncryptedStorage(s.fs, key)
+	c.Assert(err, IsNil)
+
+	_, err = storer.Reference(ref.Name())
+	c.Assert(err, Equals, ErrDecrypt)
+}
+
+func (s *EncryptedStorageSuite) TestWrapKey(c *C) {
+	kek, err := GenerateKey()
+	c.Assert(err, IsNil)
+
+	wrapped, err := WrapKey(kek, s.key)
+	c.Assert(err, IsNil)
+
+	key, err := UnwrapKey(kek, wrapped)
+	c.Assert(err, IsNil)
+	c.Assert(key, DeepEquals, s.key)
+
+	_, err = UnwrapKey(s.key, wrapped)
+	c.Assert(err, Equals, ErrDecrypt)
+}